@@ -0,0 +1,298 @@
+package app
+
+import (
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"faucet/internal"
+	"faucet/internal/acl"
+	"faucet/internal/certs"
+	"faucet/internal/errcode"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type aclInput struct {
+	List    string `json:"list"`
+	Value   string `json:"value"`
+	Comment string `json:"comment"`
+}
+
+type aclData struct {
+	Entries []*acl.Entry `json:"entries"`
+}
+
+type netInput struct {
+	Net string `json:"net"`
+}
+
+type amountInput struct {
+	Net    string  `json:"net"`
+	Amount float64 `json:"amount"`
+}
+
+type sendInput struct {
+	Net          string `json:"net"`
+	Address      string `json:"address"`
+	SkipCooldown bool   `json:"skipCooldown"`
+}
+
+type backupData struct {
+	Path    string `json:"path"`
+	Records int    `json:"records"`
+}
+
+type pendingData struct {
+	Txs []*internal.PendingTx `json:"txs"`
+}
+
+// registerAdmin 注册 /admin 路由，配置了单独端口时使用独立的 gin 实例监听
+func (g *Server) registerAdmin() error {
+	cfg := g.client.Config.Admin
+	if cfg.Port == "" && !cfg.AllowPublicPort {
+		return fmt.Errorf("admin.port is empty, set admin.allow_public_port to serve /admin on the public port")
+	}
+	if cfg.ClientCA != "" {
+		pool, err := certs.LoadCertPool(filepath.Join(g.client.Config.RepoRoot, cfg.ClientCA))
+		if err != nil {
+			return fmt.Errorf("admin: %w", err)
+		}
+		g.adminCAs = pool
+	}
+	router := g.router
+	if cfg.Port != "" {
+		router = gin.New()
+		router.Use(gin.Recovery()).Use(g.Drain())
+	}
+
+	g.adminRoutes(router.Group("/admin", g.AdminAuth(), g.Validate()))
+
+	if cfg.Port == "" {
+		return nil
+	}
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%s", cfg.Port))
+	if err != nil {
+		return fmt.Errorf("admin listen: %w", err)
+	}
+	if cfg.TLSCert != "" {
+		tlsConfig, err := g.adminTLSConfig()
+		if err != nil {
+			ln.Close()
+			return err
+		}
+		ln = tls.NewListener(ln, tlsConfig)
+	}
+	g.serve("admin api", ln, router)
+	return nil
+}
+
+// adminRoutes 注册 /admin 下的管理接口，接口定义见 internal/openapi/openapi.json
+func (g *Server) adminRoutes(admin *gin.RouterGroup) {
+	admin.GET("acl", g.listACL)
+	admin.POST("acl", g.addACL)
+	admin.DELETE("acl", g.removeACL)
+	admin.POST("pause", g.pause)
+	admin.POST("resume", g.resume)
+	admin.DELETE("cooldown", g.resetCooldown)
+	admin.GET("pending", g.pendingTxs)
+	admin.PUT("amount", g.setAmount)
+	admin.POST("send", g.manualSend)
+	admin.POST("backup", g.backup)
+	admin.GET("audit", g.listAudit)
+	admin.GET("audit/verify", g.verifyAudit)
+	admin.GET("services", g.services)
+}
+
+// adminTLSConfig 配置了 client_ca 时强制校验客户端证书
+func (g *Server) adminTLSConfig() (*tls.Config, error) {
+	cfg := g.client.Config.Admin
+	reloader, err := g.loadCert(cfg.TLSCert, cfg.TLSKey)
+	if err != nil {
+		return nil, fmt.Errorf("load admin tls cert: %w", err)
+	}
+	tlsConfig := reloader.TLSConfig()
+	if g.adminCAs != nil {
+		tlsConfig.ClientCAs = g.adminCAs
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
+
+// adminCertVerified 用 admin.client_ca 重新校验客户端证书，监听端口信任的其他 CA 签发的证书不能访问 /admin
+func (g *Server) adminCertVerified(state *tls.ConnectionState) bool {
+	if g.adminCAs == nil || state == nil || len(state.PeerCertificates) == 0 {
+		return false
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         g.adminCAs,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	return err == nil
+}
+
+// AdminAuth 校验 admin.client_ca 签发的客户端证书或 Authorization: Bearer <token>，require_client_cert 时只接受证书
+func (g *Server) AdminAuth() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		if err := g.authorizeAdmin(c.Request.TLS, c.GetHeader("Authorization")); err != nil {
+			g.logger.Warnf("admin unauthorized request from %s: %s", g.clientIp(c), err)
+			fail(c, err)
+			return
+		}
+		c.Next()
+	}
+}
+
+// authorizeAdmin 管理员认证，http 和 gRPC 接口共用
+func (g *Server) authorizeAdmin(state *tls.ConnectionState, authorization string) error {
+	if g.adminCertVerified(state) {
+		return nil
+	}
+	if g.client.Config.Admin.RequireClientCert {
+		return errcode.New(errcode.Unauthorized, "client certificate required")
+	}
+	if token, ok := bearerToken(authorization); ok {
+		for _, allowed := range g.client.Config.Admin.Tokens {
+			if allowed != "" && subtle.ConstantTimeCompare([]byte(token), []byte(allowed)) == 1 {
+				return nil
+			}
+		}
+	}
+	return errcode.New(errcode.Unauthorized, "unauthorized")
+}
+
+// bearerToken 解析 Authorization: Bearer <token>，scheme 不区分大小写，缺少 scheme 的裸 token 不接受
+func bearerToken(header string) (string, bool) {
+	const scheme = "Bearer "
+	if len(header) <= len(scheme) || !strings.EqualFold(header[:len(scheme)], scheme) {
+		return "", false
+	}
+	return header[len(scheme):], true
+}
+
+func (g *Server) listACL(c *gin.Context) {
+	ok(c, &aclData{Entries: g.client.ACL.Entries(c.Query("list"))})
+}
+
+func (g *Server) addACL(c *gin.Context) {
+	var input aclInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		fail(c, errcode.Wrap(errcode.InvalidRequest, err))
+		return
+	}
+	entry, err := g.client.ACL.Add(input.List, input.Value, input.Comment)
+	if err != nil {
+		fail(c, errcode.Wrap(errcode.InvalidRequest, err))
+		return
+	}
+	g.logger.Infof("admin add %s to %s list", entry.Value, entry.List)
+	ok(c, &aclData{Entries: []*acl.Entry{entry}})
+}
+
+func (g *Server) removeACL(c *gin.Context) {
+	var input aclInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		fail(c, errcode.Wrap(errcode.InvalidRequest, err))
+		return
+	}
+	if err := g.client.ACL.Remove(input.List, input.Value); err != nil {
+		fail(c, errcode.Wrap(errcode.InvalidRequest, err))
+		return
+	}
+	g.logger.Infof("admin remove %s from %s list", input.Value, input.List)
+	ok(c, nil)
+}
+
+func (g *Server) pause(c *gin.Context) {
+	var input netInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		fail(c, errcode.Wrap(errcode.InvalidRequest, err))
+		return
+	}
+	g.client.Pause(input.Net)
+	ok(c, nil)
+}
+
+func (g *Server) resume(c *gin.Context) {
+	var input netInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		fail(c, errcode.Wrap(errcode.InvalidRequest, err))
+		return
+	}
+	g.client.Resume(input.Net)
+	ok(c, nil)
+}
+
+// resetCooldown 删除地址的领取记录
+func (g *Server) resetCooldown(c *gin.Context) {
+	net := c.Query("net")
+	address := c.Query("address")
+	if err := g.client.ResetCooldown(net, address); err != nil {
+		fail(c, err)
+		return
+	}
+	g.logger.Infof("admin reset cooldown of %s on %s", address, net)
+	ok(c, nil)
+}
+
+func (g *Server) pendingTxs(c *gin.Context) {
+	txs, err := g.client.PendingTxs()
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	ok(c, &pendingData{Txs: txs})
+}
+
+func (g *Server) setAmount(c *gin.Context) {
+	var input amountInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		fail(c, errcode.Wrap(errcode.InvalidRequest, err))
+		return
+	}
+	if err := g.client.SetAmount(input.Net, input.Amount); err != nil {
+		fail(c, err)
+		return
+	}
+	ok(c, nil)
+}
+
+// manualSend 管理员手动发放，不经过人机验证和名单检查
+func (g *Server) manualSend(c *gin.Context) {
+	var input sendInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		fail(c, errcode.Wrap(errcode.InvalidRequest, err))
+		return
+	}
+	txHash, err := g.client.SendTra(&internal.DripRequest{
+		Net:          input.Net,
+		Address:      input.Address,
+		Source:       sourceAdmin,
+		UserAgent:    c.Request.UserAgent(),
+		SkipCooldown: input.SkipCooldown,
+	})
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	g.logger.Infof("admin manual send to %s on %s: %s", input.Address, input.Net, txHash)
+	ok(c, &dripData{TxHash: txHash})
+}
+
+// backup 在线备份 store，备份期间不影响领取
+func (g *Server) backup(c *gin.Context) {
+	path, count, err := g.client.Backup()
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	ok(c, &backupData{Path: path, Records: count})
+}
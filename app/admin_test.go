@@ -0,0 +1,136 @@
+package app
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"faucet/internal"
+	"faucet/internal/repo"
+	"faucet/internal/utils"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func newAdminServer(t *testing.T, cfg repo.Admin) *Server {
+	resolver, err := utils.NewIpResolver(nil, false)
+	require.Nil(t, err)
+	return &Server{
+		router:     gin.New(),
+		client:     &internal.Client{Config: &repo.Config{Admin: cfg}},
+		logger:     logrus.New(),
+		ipResolver: resolver,
+	}
+}
+
+func TestAdminAuth(t *testing.T) {
+	g := newAdminServer(t, repo.Admin{Enable: true, Tokens: []string{"secret"}})
+	router := gin.New()
+	router.GET("/admin/pending", g.AdminAuth(), func(c *gin.Context) { ok(c, nil) })
+
+	cases := []struct {
+		header string
+		status int
+	}{
+		{"Bearer secret", http.StatusOK},
+		{"bearer secret", http.StatusOK},
+		{"secret", http.StatusUnauthorized},
+		{"Bearer other", http.StatusUnauthorized},
+		{"Bearer ", http.StatusUnauthorized},
+		{"", http.StatusUnauthorized},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/admin/pending", nil)
+		if c.header != "" {
+			req.Header.Set("Authorization", c.header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, c.status, w.Code, c.header)
+	}
+}
+
+func TestAdminAuthRequireClientCert(t *testing.T) {
+	g := newAdminServer(t, repo.Admin{Enable: true, Tokens: []string{"secret"}, RequireClientCert: true})
+	router := gin.New()
+	router.GET("/admin/pending", g.AdminAuth(), func(c *gin.Context) { ok(c, nil) })
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/pending", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRegisterAdminRequiresPort(t *testing.T) {
+	g := newAdminServer(t, repo.Admin{Enable: true, Tokens: []string{"secret"}})
+	require.NotNil(t, g.registerAdmin())
+
+	g = newAdminServer(t, repo.Admin{Enable: true, Tokens: []string{"secret"}, AllowPublicPort: true})
+	require.Nil(t, g.registerAdmin())
+	var routes []string
+	for _, route := range g.router.Routes() {
+		routes = append(routes, route.Path)
+	}
+	require.Contains(t, routes, "/admin/pending")
+}
+
+// issueCert 签发证书，parent 为空时生成自签名的 CA
+func issueCert(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		tmpl.IsCA = true
+		tmpl.BasicConstraintsValid = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	require.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+	return cert, key
+}
+
+func TestAdminAuthClientCert(t *testing.T) {
+	adminCA, adminKey := issueCert(t, "admin ca", nil, nil)
+	publicCA, publicKey := issueCert(t, "public ca", nil, nil)
+	operator, _ := issueCert(t, "operator", adminCA, adminKey)
+	user, _ := issueCert(t, "user", publicCA, publicKey)
+
+	g := newAdminServer(t, repo.Admin{Enable: true, RequireClientCert: true})
+	g.adminCAs = x509.NewCertPool()
+	g.adminCAs.AddCert(adminCA)
+	router := gin.New()
+	router.GET("/admin/pending", g.AdminAuth(), func(c *gin.Context) { ok(c, nil) })
+
+	request := func(leaf *x509.Certificate, ca *x509.Certificate) int {
+		req := httptest.NewRequest(http.MethodGet, "/admin/pending", nil)
+		// 监听端口已经校验过证书链，AdminAuth 仍然只认 admin.client_ca
+		req.TLS = &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{leaf},
+			VerifiedChains:   [][]*x509.Certificate{{leaf, ca}},
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, request(operator, adminCA))
+	require.Equal(t, http.StatusUnauthorized, request(user, publicCA))
+}
@@ -0,0 +1,51 @@
+package app
+
+import (
+	"faucet/internal/apikey"
+	"faucet/internal/repo"
+
+	"github.com/gin-gonic/gin"
+)
+
+const apiKeyContextKey = "apiKey"
+
+// APIKeyAuth 校验 X-Api-Key，未携带时按匿名请求处理，携带了无效或已吊销的 key 直接拒绝
+func (g *Server) APIKeyAuth() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		secret := c.GetHeader(apiKeyHeader)
+		if secret == "" {
+			c.Next()
+			return
+		}
+		key, _, err := g.lookupAPIKey(secret)
+		if err != nil {
+			fail(c, err)
+			return
+		}
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
+
+// lookupAPIKey 查找 key 及其所属的 tier，http 和 gRPC 接口共用
+func (g *Server) lookupAPIKey(secret string) (*apikey.Key, *repo.Tier, error) {
+	key, err := g.client.APIKeys.Lookup(secret)
+	if err != nil {
+		return nil, nil, err
+	}
+	tier, ok := g.client.Config.Axiom.Tier(key.Tier)
+	if !ok {
+		g.logger.Errorf("api key %s refers to unknown tier %s", key.ID, key.Tier)
+		return nil, nil, apikey.ErrInvalidKey
+	}
+	return key, tier, nil
+}
+
+// requestAPIKey 返回 APIKeyAuth 校验通过的 key，匿名请求返回 nil
+func requestAPIKey(c *gin.Context) *apikey.Key {
+	value, ok := c.Get(apiKeyContextKey)
+	if !ok {
+		return nil
+	}
+	return value.(*apikey.Key)
+}
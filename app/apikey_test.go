@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"faucet/internal"
+	"faucet/internal/apikey"
+	"faucet/internal/captcha"
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"faucet/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+type rejectCaptcha struct{}
+
+func (rejectCaptcha) Verify(ctx context.Context, token string, remoteIP string) error {
+	return errors.New("captcha rejected")
+}
+
+func TestCaptchaSkippedForAPIKey(t *testing.T) {
+	keys := apikey.New(store.New(store.NewMemory()).APIKeys())
+	secret, _, err := keys.Issue("ci", "ci")
+	require.Nil(t, err)
+	resolver, err := utils.NewIpResolver(nil, false)
+	require.Nil(t, err)
+	g := &Server{
+		client: &internal.Client{
+			Config:  &repo.Config{Axiom: repo.AXIOM{Tiers: []repo.Tier{{Name: "ci"}}}},
+			APIKeys: keys,
+		},
+		logger:     logrus.New(),
+		captcha:    map[string]captcha.Verifier{"axm": rejectCaptcha{}},
+		ipResolver: resolver,
+	}
+	router := gin.New()
+	router.POST("/faucet/v1/nativeToken", g.APIKeyAuth(), g.CaptchaVerify(), func(c *gin.Context) { ok(c, nil) })
+
+	drip := func(secret string) int {
+		body := `{"net":"axm","address":"0x0000000000000000000000000000000000000001"}`
+		req := httptest.NewRequest(http.MethodPost, "/faucet/v1/nativeToken", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if secret != "" {
+			req.Header.Set(apiKeyHeader, secret)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, drip(secret))
+	require.NotEqual(t, http.StatusOK, drip(""))
+}
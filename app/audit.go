@@ -0,0 +1,120 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"faucet/internal/audit"
+	"faucet/internal/errcode"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	auditedContextKey = "audited"
+	sourceAPI         = "api"
+	sourceAdmin       = "admin"
+	// maxCapturedBody 只需要解析响应中的 code 和 detail，超过部分不保存
+	maxCapturedBody = 4096
+)
+
+type auditData struct {
+	Entries []*audit.Entry `json:"entries"`
+}
+
+type verifyData struct {
+	Verified int `json:"verified"`
+}
+
+// bodyWriter 保留响应体的前 maxCapturedBody 字节，用于提取拒绝原因
+type bodyWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyWriter) Write(data []byte) (int, error) {
+	if remain := maxCapturedBody - w.body.Len(); remain > 0 {
+		if len(data) < remain {
+			remain = len(data)
+		}
+		w.body.Write(data[:remain])
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// Audit 记录在交易发送前就被中间件或参数校验拒绝的请求，进入 SendTra 的请求由 client 自己记录。
+// 这类拒绝来自匿名请求，按 ip 和错误码每分钟只记录第一条，避免刷接口时账本无限增长
+func (g *Server) Audit() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		writer := &bodyWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		if c.GetBool(auditedContextKey) {
+			return
+		}
+
+		var input nativeInput
+		_ = c.ShouldBindBodyWith(&input, binding.JSON)
+		var res envelope
+		_ = json.Unmarshal(writer.body.Bytes(), &res)
+		entry := &audit.Entry{
+			Source:    sourceAPI,
+			Net:       input.Net,
+			Address:   input.Address,
+			IP:        g.clientIp(c),
+			UserAgent: c.Request.UserAgent(),
+			Decision:  audit.DecisionDeny,
+			Reason:    fmt.Sprintf("%s %s", res.Code, res.Detail),
+			Status:    audit.StatusRejected,
+		}
+		if key := requestAPIKey(c); key != nil {
+			entry.APIKey = key.ID
+		}
+		g.recordReject(entry, res.Code)
+	}
+}
+
+// recordReject 写入发送交易前的拒绝记录，同一分钟内同一个 ip 的同一种拒绝只保留第一条
+func (g *Server) recordReject(entry *audit.Entry, code errcode.Code) {
+	if !g.limiters.rejects.Allow(entry.IP + "|" + string(code)).Allowed {
+		return
+	}
+	g.client.RecordAudit(entry)
+}
+
+// listAudit 查询 [from, to) 内的审计记录，format=csv 时以 CSV 文件下载
+func (g *Server) listAudit(c *gin.Context) {
+	from, to, err := audit.ParseTimeRange(c.Query("from"), c.Query("to"))
+	if err != nil {
+		fail(c, errcode.Wrap(errcode.InvalidRequest, err))
+		return
+	}
+	entries, err := g.client.Audit.Range(from, to)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	if c.Query("format") != "csv" {
+		ok(c, &auditData{Entries: entries})
+		return
+	}
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=audit-%s.csv", time.Now().Format("20060102-150405")))
+	c.Status(http.StatusOK)
+	if err := audit.WriteCSV(c.Writer, entries); err != nil {
+		g.logger.Errorf("write audit csv: %s", err)
+	}
+}
+
+func (g *Server) verifyAudit(c *gin.Context) {
+	count, err := g.client.Audit.Verify()
+	if err != nil {
+		g.logger.Errorf("audit ledger verified %d entries before: %s", count, err)
+		fail(c, err)
+		return
+	}
+	ok(c, &verifyData{Verified: count})
+}
@@ -0,0 +1,50 @@
+package app
+
+import (
+	"faucet/internal"
+	"faucet/internal/audit"
+	"faucet/internal/errcode"
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"faucet/internal/utils"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditCoalescesRejects(t *testing.T) {
+	resolver, err := utils.NewIpResolver(nil, false)
+	require.Nil(t, err)
+	ledger := audit.New(store.New(store.NewMemory()).Audit())
+	g := &Server{
+		client:     &internal.Client{Config: &repo.Config{}, Audit: ledger},
+		logger:     logrus.New(),
+		limiters:   newRateLimiters(repo.RateLimit{}),
+		ipResolver: resolver,
+	}
+	router := gin.New()
+	router.POST("/faucet/v1/nativeToken", g.Audit(), func(c *gin.Context) {
+		fail(c, errcode.New(errcode.InvalidAddress, "invalid address"))
+	})
+
+	drip := func(ip string) {
+		req := httptest.NewRequest(http.MethodPost, "/faucet/v1/nativeToken", nil)
+		req.RemoteAddr = ip + ":1234"
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	for i := 0; i < 5; i++ {
+		drip("10.0.0.1")
+	}
+	drip("10.0.0.2")
+
+	entries, err := ledger.Range(time.Unix(0, 0), time.Now().Add(time.Minute))
+	require.Nil(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "10.0.0.1", entries[0].IP)
+	require.Equal(t, "10.0.0.2", entries[1].IP)
+}
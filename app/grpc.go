@@ -0,0 +1,389 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"faucet/internal"
+	"faucet/internal/acl"
+	"faucet/internal/audit"
+	"faucet/internal/errcode"
+	"faucet/internal/i18n"
+	"faucet/internal/loggers"
+	"faucet/internal/ratelimit"
+	"faucet/internal/utils"
+	"faucet/pb"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	sourceGRPC = "grpc"
+	// errorDomain 写入 ErrorInfo.Domain
+	errorDomain = "faucet"
+	// eventBuffer 每个 StreamClaimEvents 订阅的缓冲，客户端消费过慢时丢弃事件
+	eventBuffer = 256
+)
+
+// GRPCServer 在单独端口上提供 gRPC 接口，限流、api key、人机验证、名单和发放逻辑与 http 接口共用
+type GRPCServer struct {
+	pb.UnimplementedFaucetServer
+
+	server *Server
+	grpc   *grpc.Server
+	logger logrus.FieldLogger
+	// done 停止时关闭，结束所有事件订阅
+	done chan struct{}
+}
+
+func NewGRPCServer(server *Server) *GRPCServer {
+	s := &GRPCServer{
+		server: server,
+		logger: loggers.Logger(loggers.ApiServer),
+		done:   make(chan struct{}),
+	}
+	return s
+}
+
+// Start 公网端口启用 https 时 gRPC 使用同一份证书，/admin 挂在公网端口时同样校验管理员客户端证书
+func (s *GRPCServer) Start() error {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(s.rateLimit),
+		grpc.ChainStreamInterceptor(s.streamRateLimit),
+	}
+	if s.server.client.Config.Network.TLS.Enable {
+		tlsConfig, err := s.server.publicTLSConfig()
+		if err != nil {
+			return fmt.Errorf("grpc tls: %w", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	s.grpc = grpc.NewServer(opts...)
+	pb.RegisterFaucetServer(s.grpc, s)
+
+	port := s.server.client.Config.GRPC.Port
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
+	if err != nil {
+		return fmt.Errorf("grpc listen: %w", err)
+	}
+	go func() {
+		s.logger.Infof("start grpc api on port %s", port)
+		if err := s.grpc.Serve(ln); err != nil {
+			s.logger.Errorf("grpc serve: %s", err)
+		}
+	}()
+	return nil
+}
+
+func (s *GRPCServer) Stop() error {
+	close(s.done)
+	if s.grpc == nil {
+		return nil
+	}
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(s.server.client.Config.Shutdown.Timeout):
+		// 超时后强制断开仍未结束的请求
+		s.grpc.Stop()
+	}
+	s.logger.Infoln("grpc service stop")
+	return nil
+}
+
+func (s *GRPCServer) RequestDrip(ctx context.Context, req *pb.RequestDripRequest) (res *pb.RequestDripResponse, err error) {
+	g := s.server
+	drip := &internal.DripRequest{
+		Net:       req.Net,
+		Address:   req.Address,
+		Source:    sourceGRPC,
+		IP:        peerIp(ctx),
+		UserAgent: metadataValue(ctx, "user-agent"),
+	}
+	sent := false
+	defer func() {
+		if err == nil {
+			return
+		}
+		// 进入 SendTra 的请求由 client 自己记录审计
+		if !sent {
+			e := codeError(err)
+			entry := &audit.Entry{
+				Source:    sourceGRPC,
+				Net:       drip.Net,
+				Address:   drip.Address,
+				IP:        drip.IP,
+				UserAgent: drip.UserAgent,
+				Decision:  audit.DecisionDeny,
+				Reason:    fmt.Sprintf("%s %s", e.Code, e.Msg),
+				Status:    audit.StatusRejected,
+			}
+			if drip.APIKey != nil {
+				entry.APIKey = drip.APIKey.ID
+			}
+			g.recordReject(entry, e.Code)
+		}
+		err = s.status(ctx, err)
+	}()
+
+	if err := g.spec.ValidateValue("Net", "net", req.Net); err != nil {
+		return nil, validationError(err)
+	}
+	if err := g.spec.ValidateValue("Address", "address", req.Address); err != nil {
+		return nil, validationError(err)
+	}
+	key := fmt.Sprintf("%s:%s", strings.ToLower(req.Net), strings.ToLower(req.Address))
+	if err := allowRate(g.limiters.address, key, errcode.RateLimited); err != nil {
+		return nil, err
+	}
+	if secret := metadataValue(ctx, apiKeyHeader); secret != "" {
+		drip.APIKey, drip.Tier, err = g.lookupAPIKey(secret)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if verifier, found := g.captcha[strings.ToLower(req.Net)]; found && drip.APIKey == nil {
+		if err := verifier.Verify(ctx, req.CaptchaToken, drip.IP); err != nil {
+			return nil, err
+		}
+	}
+	decision, err := g.authorizeDrip(req.Net, req.Address, req.Signature, drip.IP)
+	if err != nil {
+		return nil, err
+	}
+	drip.SkipCooldown = decision == acl.Allowed
+
+	sent = true
+	txHash, err := g.client.SendTra(drip)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.RequestDripResponse{TxHash: txHash}, nil
+}
+
+func (s *GRPCServer) GetClaimStatus(ctx context.Context, req *pb.GetClaimStatusRequest) (*pb.ClaimStatus, error) {
+	if err := s.server.spec.ValidateValue("TxHash", "txHash", req.TxHash); err != nil {
+		return nil, s.status(ctx, validationError(err))
+	}
+	claim, err := s.server.client.ClaimStatus(req.TxHash)
+	if err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return &pb.ClaimStatus{TxHash: claim.TxHash, Status: claim.Status, BlockNumber: claim.BlockNumber}, nil
+}
+
+func (s *GRPCServer) GetFaucetInfo(ctx context.Context, _ *pb.GetFaucetInfoRequest) (*pb.FaucetInfo, error) {
+	info, err := s.server.faucetInfo(requestLang(ctx))
+	if err != nil {
+		return nil, s.status(ctx, err)
+	}
+	res := &pb.FaucetInfo{}
+	for _, n := range info.Networks {
+		network := &pb.NetworkInfo{
+			Net:    n.Net,
+			Amount: n.Amount,
+			Paused: n.Paused,
+			Budget: &pb.Budget{
+				Hourly:          n.Budget.Hourly,
+				HourlyRemaining: n.Budget.HourlyRemaining,
+				Daily:           n.Budget.Daily,
+				DailyRemaining:  n.Budget.DailyRemaining,
+			},
+			Balance:     n.Balance,
+			Ownership:   n.Ownership,
+			Description: n.Description,
+		}
+		if n.Captcha != nil {
+			network.Captcha = &pb.Captcha{Provider: n.Captcha.Provider, SiteKey: n.Captcha.SiteKey}
+		}
+		res.Networks = append(res.Networks, network)
+	}
+	return res, nil
+}
+
+// StreamClaimEvents 推送审计账本的新记录，直到客户端断开或服务停止。
+// 记录包含所有用户的地址和拒绝原因，只对携带有效 api key 或管理员凭证的调用方开放
+func (s *GRPCServer) StreamClaimEvents(req *pb.StreamClaimEventsRequest, stream pb.Faucet_StreamClaimEventsServer) error {
+	ctx := stream.Context()
+	if err := s.authorizeEvents(ctx); err != nil {
+		return s.status(ctx, err)
+	}
+	events, cancel := s.server.client.Audit.Subscribe(eventBuffer)
+	defer cancel()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.done:
+			return status.Error(codes.Unavailable, "server is stopping")
+		case entry := <-events:
+			if req.Net != "" && !strings.EqualFold(req.Net, entry.Net) {
+				continue
+			}
+			if req.Address != "" && !strings.EqualFold(req.Address, entry.Address) {
+				continue
+			}
+			err := stream.Send(&pb.ClaimEvent{
+				Seq:      entry.Seq,
+				Time:     entry.Time,
+				Source:   entry.Source,
+				Net:      entry.Net,
+				Address:  entry.Address,
+				Decision: entry.Decision,
+				Reason:   entry.Reason,
+				TxHash:   entry.TxHash,
+				Amount:   entry.Amount,
+				Status:   entry.Status,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// authorizeEvents 事件流需要有效的 api key，或与 /admin 相同的管理员证书、token
+func (s *GRPCServer) authorizeEvents(ctx context.Context) error {
+	if secret := metadataValue(ctx, apiKeyHeader); secret != "" {
+		_, _, err := s.server.lookupAPIKey(secret)
+		return err
+	}
+	var state *tls.ConnectionState
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			state = &info.State
+		}
+	}
+	return s.server.authorizeAdmin(state, metadataValue(ctx, "authorization"))
+}
+
+// rateLimit 与 http 的 RateLimit 中间件一致，依次按全局、客户端 ip 所在网段、api key 消耗令牌
+func (s *GRPCServer) rateLimit(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := s.allow(ctx); err != nil {
+		return nil, s.status(ctx, err)
+	}
+	return handler(ctx, req)
+}
+
+// streamRateLimit 建立流时按 rateLimit 的规则消耗一个令牌
+func (s *GRPCServer) streamRateLimit(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.allow(ss.Context()); err != nil {
+		return s.status(ss.Context(), err)
+	}
+	return handler(srv, ss)
+}
+
+func (s *GRPCServer) allow(ctx context.Context) error {
+	g := s.server
+	cfg := g.client.Config.RateLimit
+	if err := allowRate(g.limiters.global, "", errcode.Overloaded); err != nil {
+		return err
+	}
+	subnet := utils.SubnetKey(peerIp(ctx), cfg.IPv4Prefix, cfg.IPv6Prefix)
+	if err := allowRate(g.limiters.ip, subnet, errcode.RateLimited); err != nil {
+		return err
+	}
+	if key := metadataValue(ctx, apiKeyHeader); key != "" {
+		if err := allowRate(g.limiters.apiKey, key, errcode.RateLimited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func allowRate(limiter *ratelimit.Limiter, key string, code errcode.Code) error {
+	if limiter == nil {
+		return nil
+	}
+	if res := limiter.Allow(key); !res.Allowed {
+		return errcode.New(code, "too many requests").WithRetry(res.RetryAfter)
+	}
+	return nil
+}
+
+// status 把错误转换为 gRPC status：message 为翻译后的提示，ErrorInfo 中带错误码和原始错误，可重试时带 RetryInfo
+func (s *GRPCServer) status(ctx context.Context, err error) error {
+	e := codeError(err)
+	st := status.New(grpcCode(e.Code), i18n.Message(requestLang(ctx), string(e.Code)))
+	details := []protoiface.MessageV1{
+		&errdetails.ErrorInfo{Reason: string(e.Code), Domain: errorDomain, Metadata: map[string]string{"detail": e.Msg}},
+	}
+	if e.RetryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(e.RetryAfter)})
+	}
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		s.logger.Errorf("attach grpc error details: %s", err)
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+// grpcCode 按错误码对应的 http 状态码选择 gRPC code
+func grpcCode(code errcode.Code) codes.Code {
+	switch code.Status() {
+	case http.StatusOK:
+		return codes.OK
+	case http.StatusBadRequest:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.Aborted
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	return codes.Internal
+}
+
+func requestLang(ctx context.Context) string {
+	return i18n.Negotiate(metadataValue(ctx, "lang"), metadataValue(ctx, "accept-language"))
+}
+
+func metadataValue(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// peerIp gRPC 接口面向内网服务，直接使用连接的对端地址
+func peerIp(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
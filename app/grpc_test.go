@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"faucet/internal"
+	"faucet/internal/apikey"
+	"faucet/internal/errcode"
+	"faucet/internal/openapi"
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"faucet/pb"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCStatus(t *testing.T) {
+	s := &GRPCServer{logger: logrus.New()}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("accept-language", "zh-CN"))
+
+	err := s.status(ctx, errcode.New(errcode.RateLimited, "too many requests").WithRetry(1500*time.Millisecond))
+	st := status.Convert(err)
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Equal(t, "请求过于频繁，请稍后再试", st.Message())
+	require.Len(t, st.Details(), 2)
+	info := st.Details()[0].(*errdetails.ErrorInfo)
+	require.Equal(t, string(errcode.RateLimited), info.Reason)
+	require.Equal(t, "too many requests", info.Metadata["detail"])
+	retry := st.Details()[1].(*errdetails.RetryInfo)
+	require.Equal(t, 1500*time.Millisecond, retry.RetryDelay.AsDuration())
+
+	st = status.Convert(s.status(context.Background(), errcode.New(errcode.FaucetPaused, "paused")))
+	require.Equal(t, codes.Unavailable, st.Code())
+	require.Len(t, st.Details(), 1)
+}
+
+func TestGRPCValidation(t *testing.T) {
+	spec, err := openapi.Load()
+	require.Nil(t, err)
+	s := &GRPCServer{server: &Server{spec: spec}, logger: logrus.New()}
+	_, err = s.GetClaimStatus(context.Background(), &pb.GetClaimStatusRequest{TxHash: "0x12"})
+	st := status.Convert(err)
+	require.Equal(t, codes.InvalidArgument, st.Code())
+	require.Equal(t, string(errcode.InvalidRequest), st.Details()[0].(*errdetails.ErrorInfo).Reason)
+}
+
+func TestGRPCCode(t *testing.T) {
+	for _, code := range errcode.Codes() {
+		require.NotEqual(t, codes.Unknown, grpcCode(code))
+	}
+	require.Equal(t, codes.PermissionDenied, grpcCode(errcode.Blocked))
+	require.Equal(t, codes.Unauthenticated, grpcCode(errcode.InvalidAPIKey))
+	require.Equal(t, codes.Internal, grpcCode(errcode.Internal))
+}
+
+func TestGRPCEventsAuth(t *testing.T) {
+	keys := apikey.New(store.New(store.NewMemory()).APIKeys())
+	secret, _, err := keys.Issue("ci", "ci")
+	require.Nil(t, err)
+	s := &GRPCServer{server: &Server{client: &internal.Client{
+		Config: &repo.Config{
+			Axiom: repo.AXIOM{Tiers: []repo.Tier{{Name: "ci"}}},
+			Admin: repo.Admin{Enable: true, Tokens: []string{"secret"}},
+		},
+		APIKeys: keys,
+	}}, logger: logrus.New()}
+
+	incoming := func(kv ...string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(kv...))
+	}
+	require.Equal(t, codes.Unauthenticated, status.Code(s.status(context.Background(), s.authorizeEvents(context.Background()))))
+	require.NotNil(t, s.authorizeEvents(incoming("x-api-key", "fct_unknown")))
+	require.NotNil(t, s.authorizeEvents(incoming("authorization", "secret")))
+	require.Nil(t, s.authorizeEvents(incoming("x-api-key", secret)))
+	require.Nil(t, s.authorizeEvents(incoming("authorization", "Bearer secret")))
+}
+
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
+
+func TestGRPCStreamRateLimit(t *testing.T) {
+	s := &GRPCServer{server: &Server{
+		client:   &internal.Client{Config: &repo.Config{}},
+		limiters: newRateLimiters(repo.RateLimit{IP: repo.Rate{Limit: 1, Period: time.Minute, Burst: 1}}),
+	}, logger: logrus.New()}
+	stream := &contextStream{ctx: peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1}})}
+	handler := func(srv interface{}, stream grpc.ServerStream) error { return nil }
+
+	require.Nil(t, s.streamRateLimit(nil, stream, &grpc.StreamServerInfo{}, handler))
+	err := s.streamRateLimit(nil, stream, &grpc.StreamServerInfo{}, handler)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -0,0 +1,53 @@
+package app
+
+import (
+	"faucet/internal"
+
+	"github.com/gin-gonic/gin"
+)
+
+type servicesData struct {
+	Services []*internal.ServiceInfo `json:"services"`
+}
+
+// NewNode 构造水龙头的全部服务并注册到 Node：client 最先启动、最后关闭，
+// 接口服务最后启动、最先进入排空
+func NewNode(client *internal.Client) (*internal.Node, error) {
+	server, err := NewServer(client)
+	if err != nil {
+		return nil, err
+	}
+	node := internal.NewNode()
+	server.node = node
+
+	register := func(name string, lifecycle internal.Lifecycle, deps ...string) {
+		if err == nil {
+			err = node.RegisterLifecycle(name, lifecycle, deps...)
+		}
+	}
+	register("client", client)
+	register("janitor", internal.NewJanitor(client.Store(), client.Config), "client")
+	register("tracker", internal.NewTracker(client), "client")
+	if client.Config.Metrics.Enable {
+		register("metrics", internal.NewMetrics(client), "client")
+	}
+	if client.Config.Notifier.Enable {
+		register("notifier", internal.NewNotifier(client), "client")
+	}
+	register("api", server, "client")
+	if client.Config.GRPC.Enable {
+		register("grpc", NewGRPCServer(server), "api")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (g *Server) services(c *gin.Context) {
+	data := &servicesData{Services: []*internal.ServiceInfo{}}
+	if g.node != nil {
+		data.Services = g.node.Status()
+	}
+	ok(c, data)
+}
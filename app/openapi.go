@@ -0,0 +1,80 @@
+package app
+
+import (
+	"bytes"
+	"faucet/internal/errcode"
+	"faucet/internal/openapi"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serveOpenAPI 对外提供 OpenAPI 文档，生成的客户端和第三方工具以它为准
+func serveOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openapi.Raw())
+}
+
+// maxBodyBytes 请求体的上限，所有接口的请求体都只有几个字段
+const maxBodyBytes = 8 << 10
+
+// Validate 按 OpenAPI 文档校验请求参数和请求体，文档中没有的路由直接放行。
+// 请求体超过 maxBodyBytes 时拒绝，读取后写回 gin 的缓存，后续的 ShouldBindBodyWith 和 ShouldBindJSON 仍然可以读取
+func (g *Server) Validate() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		op, found := g.spec.Operation(c.Request.Method, specPath(c.FullPath()))
+		if !found {
+			c.Next()
+			return
+		}
+		var body []byte
+		if c.Request.Body != nil {
+			data, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+			if err != nil {
+				fail(c, errcode.Wrap(errcode.InvalidRequest, err))
+				return
+			}
+			body = data
+			c.Set(gin.BodyBytesKey, body)
+			c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		err := g.spec.ValidateRequest(op, func(in string, name string) (string, bool) {
+			switch in {
+			case "path":
+				value := c.Param(name)
+				return value, value != ""
+			case "query":
+				return c.GetQuery(name)
+			case "header":
+				value := c.GetHeader(name)
+				return value, value != ""
+			}
+			return "", false
+		}, body)
+		if err != nil {
+			fail(c, validationError(err))
+			return
+		}
+		c.Next()
+	}
+}
+
+func validationError(err error) *errcode.Error {
+	code := errcode.InvalidRequest
+	if e, ok := err.(*openapi.ValidationError); ok && e.Code != "" {
+		code = errcode.Code(e.Code)
+	}
+	return errcode.Wrap(code, err)
+}
+
+// specPath 把 gin 的路由参数 :name 转换为 OpenAPI 的 {name}
+func specPath(fullPath string) string {
+	segments := strings.Split(fullPath, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"encoding/json"
+	"faucet/internal/errcode"
+	"faucet/internal/openapi"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIRoutesInSync(t *testing.T) {
+	spec, err := openapi.Load()
+	require.Nil(t, err)
+	g := &Server{spec: spec}
+	router := gin.New()
+	g.publicRoutes(router.Group("/faucet"))
+	g.adminRoutes(router.Group("/admin"))
+
+	var routes, documented []string
+	for _, route := range router.Routes() {
+		routes = append(routes, route.Method+" "+specPath(route.Path))
+	}
+	for _, route := range spec.Routes() {
+		documented = append(documented, route.Method+" "+route.Path)
+	}
+	sort.Strings(routes)
+	sort.Strings(documented)
+	require.Equal(t, documented, routes, "update internal/openapi/openapi.json along with the routes")
+}
+
+func TestValidate(t *testing.T) {
+	spec, err := openapi.Load()
+	require.Nil(t, err)
+	g := &Server{spec: spec}
+	router := gin.New()
+	var bound nativeInput
+	router.POST("/faucet/nativeToken", g.Validate(), func(c *gin.Context) {
+		require.Nil(t, c.ShouldBindJSON(&bound))
+		ok(c, nil)
+	})
+
+	cases := []struct {
+		body   string
+		status int
+		code   errcode.Code
+	}{
+		{`{"net":"axm","address":"0x5FbDB2315678afecb367f032d93F642f64180aa3"}`, http.StatusOK, errcode.OK},
+		{`{"net":"axm","address":"0x5FbDB"}`, http.StatusBadRequest, errcode.InvalidAddress},
+		{`{"net":"eth","address":"0x5FbDB2315678afecb367f032d93F642f64180aa3"}`, http.StatusBadRequest, errcode.UnsupportedNetwork},
+		{`not json`, http.StatusBadRequest, errcode.InvalidRequest},
+		{`{"net":"axm","address":"` + strings.Repeat("0", maxBodyBytes) + `"}`, http.StatusBadRequest, errcode.InvalidRequest},
+	}
+	for _, c := range cases {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/faucet/nativeToken", strings.NewReader(c.body)))
+		require.Equal(t, c.status, w.Code, c.body)
+		var res envelope
+		require.Nil(t, json.Unmarshal(w.Body.Bytes(), &res))
+		require.Equal(t, c.code, res.Code, c.body)
+	}
+	require.Equal(t, "axm", bound.Net)
+}
+
+func TestSpecPath(t *testing.T) {
+	require.Equal(t, "/faucet/claim/{txHash}", specPath("/faucet/claim/:txHash"))
+	require.Equal(t, "/admin/audit/verify", specPath("/admin/audit/verify"))
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"faucet/internal/errcode"
+	"faucet/internal/ratelimit"
+	"faucet/internal/repo"
+	"faucet/internal/utils"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const apiKeyHeader = "X-Api-Key"
+
+type rateLimiters struct {
+	global  *ratelimit.Limiter
+	ip      *ratelimit.Limiter
+	address *ratelimit.Limiter
+	apiKey  *ratelimit.Limiter
+	// rejects 同一个 ip 的同一种拒绝每分钟只写一条审计记录
+	rejects *ratelimit.Limiter
+}
+
+func newRateLimiters(cfg repo.RateLimit) *rateLimiters {
+	return &rateLimiters{
+		global:  ratelimit.FromConfig(cfg.Global),
+		ip:      ratelimit.FromConfig(cfg.IP),
+		address: ratelimit.FromConfig(cfg.Address),
+		apiKey:  ratelimit.FromConfig(cfg.APIKey),
+		rejects: ratelimit.New(1, time.Minute, 1),
+	}
+}
+
+// RateLimit 限流器，依次按全局、客户端 ip 所在网段、api key 消耗令牌
+func (g *Server) RateLimit() func(c *gin.Context) {
+	g.logger.Infof("rate limit: %+v", g.client.Config.RateLimit)
+	return func(c *gin.Context) {
+		// 全局超限说明服务过载，沿用 503
+		if !g.allow(c, g.limiters.global, "", errcode.Overloaded) {
+			return
+		}
+		cfg := g.client.Config.RateLimit
+		subnet := utils.SubnetKey(g.clientIp(c), cfg.IPv4Prefix, cfg.IPv6Prefix)
+		if !g.allow(c, g.limiters.ip, subnet, errcode.RateLimited) {
+			return
+		}
+		if key := c.GetHeader(apiKeyHeader); key != "" {
+			if !g.allow(c, g.limiters.apiKey, key, errcode.RateLimited) {
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// AddressRateLimit 按 net + 接收地址限流，需要先解析请求体
+func (g *Server) AddressRateLimit() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		var input nativeInput
+		if err := c.ShouldBindBodyWith(&input, binding.JSON); err != nil {
+			fail(c, errcode.Wrap(errcode.InvalidRequest, err))
+			return
+		}
+		key := fmt.Sprintf("%s:%s", strings.ToLower(input.Net), strings.ToLower(input.Address))
+		if !g.allow(c, g.limiters.address, key, errcode.RateLimited) {
+			return
+		}
+		c.Next()
+	}
+}
+
+// allow 消耗一个令牌并写入 X-RateLimit-* 响应头，超限时中止请求
+func (g *Server) allow(c *gin.Context, limiter *ratelimit.Limiter, key string, code errcode.Code) bool {
+	if limiter == nil {
+		return true
+	}
+	res := limiter.Allow(key)
+	c.Header("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(res.ResetAfter)))
+	if !res.Allowed {
+		fail(c, errcode.New(code, "too many requests").WithRetry(res.RetryAfter))
+		return false
+	}
+	return true
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"errors"
+	"faucet/internal/apikey"
+	"faucet/internal/audit"
+	"faucet/internal/budget"
+	"faucet/internal/captcha"
+	"faucet/internal/cooldown"
+	"faucet/internal/errcode"
+	"faucet/internal/i18n"
+	"faucet/internal/ownership"
+	"faucet/internal/reserve"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiVersion 响应格式的版本，字段有不兼容的变化时升级
+const apiVersion = "v1"
+
+// envelope 所有接口统一的响应格式，data 为接口自己的返回值，失败时为空
+type envelope struct {
+	APIVersion string       `json:"apiVersion"`
+	Code       errcode.Code `json:"code"`
+	// Msg 按请求语言翻译的提示，Detail 为未翻译的错误详情，便于排查
+	Msg    string      `json:"msg"`
+	Detail string      `json:"detail,omitempty"`
+	Data   interface{} `json:"data,omitempty"`
+	// RetryAfter 建议的重试等待秒数，同时写入 Retry-After 响应头
+	RetryAfter int `json:"retryAfter,omitempty"`
+}
+
+// lang 请求的语言，?lang= 优先于 Accept-Language
+func lang(c *gin.Context) string {
+	l := i18n.Negotiate(c.Query("lang"), c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", l)
+	return l
+}
+
+func ok(c *gin.Context, data interface{}) {
+	res := &envelope{APIVersion: apiVersion, Code: errcode.OK, Msg: i18n.Message(lang(c), i18n.MsgOK), Data: data}
+	c.PureJSON(errcode.OK.Status(), res)
+}
+
+// fail 按错误码写入状态码和响应体，并中止后续的中间件
+func fail(c *gin.Context, err error) {
+	e := codeError(err)
+	res := &envelope{APIVersion: apiVersion, Code: e.Code, Msg: i18n.Message(lang(c), string(e.Code)), Detail: e.Msg}
+	if e.RetryAfter > 0 {
+		res.RetryAfter = ceilSeconds(e.RetryAfter)
+		c.Header("Retry-After", strconv.Itoa(res.RetryAfter))
+	}
+	c.AbortWithStatusJSON(e.Code.Status(), res)
+}
+
+// codeError 把任意错误转换为带错误码的错误，已知的哨兵错误映射到对应的错误码，其余为 INTERNAL_ERROR
+func codeError(err error) *errcode.Error {
+	var e *errcode.Error
+	if errors.As(err, &e) {
+		return e
+	}
+	var cooldownErr *cooldown.Error
+	if errors.As(err, &cooldownErr) {
+		return errcode.Wrap(errcode.CooldownActive, err).WithRetry(time.Until(cooldownErr.RetryAt))
+	}
+	switch {
+	case errors.Is(err, apikey.ErrInvalidKey), errors.Is(err, apikey.ErrRevokedKey):
+		return errcode.Wrap(errcode.InvalidAPIKey, err)
+	case errors.Is(err, apikey.ErrBudgetExhausted):
+		return errcode.Wrap(errcode.APIKeyQuotaExceeded, err).WithRetry(errcode.UntilNextUTCDay(time.Now()))
+	case errors.Is(err, budget.ErrExhausted):
+		// 额度按分钟分桶，最早的桶最多一分钟后移出窗口
+		return errcode.Wrap(errcode.BudgetExhausted, err).WithRetry(time.Minute)
+	case errors.Is(err, audit.ErrTampered):
+		return errcode.Wrap(errcode.AuditTampered, err)
+	case errors.Is(err, reserve.ErrTooLow):
+		return errcode.Wrap(errcode.FaucetEmpty, err)
+	case errors.Is(err, captcha.ErrMissingToken), errors.Is(err, captcha.ErrVerificationFailed):
+		return errcode.Wrap(errcode.CaptchaFailed, err)
+	case errors.Is(err, ownership.ErrNoChallenge), errors.Is(err, ownership.ErrChallengeExpired),
+		errors.Is(err, ownership.ErrInvalidSignature), errors.Is(err, ownership.ErrSignerNotMatching):
+		return errcode.Wrap(errcode.OwnershipFailed, err)
+	}
+	return errcode.Wrap(errcode.Internal, err)
+}
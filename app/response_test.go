@@ -0,0 +1,41 @@
+package app
+
+import (
+	"errors"
+	"faucet/internal/apikey"
+	"faucet/internal/audit"
+	"faucet/internal/budget"
+	"faucet/internal/cooldown"
+	"faucet/internal/errcode"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeError(t *testing.T) {
+	cases := []struct {
+		err    error
+		code   errcode.Code
+		status int
+		retry  bool
+	}{
+		{errcode.New(errcode.InvalidAddress, "invalid address: %s", "0x1"), errcode.InvalidAddress, http.StatusBadRequest, false},
+		{fmt.Errorf("send: %w", errcode.New(errcode.FaucetPaused, "paused")), errcode.FaucetPaused, http.StatusServiceUnavailable, false},
+		{&cooldown.Error{RetryAt: time.Now().Add(time.Hour)}, errcode.CooldownActive, http.StatusTooManyRequests, true},
+		{apikey.ErrRevokedKey, errcode.InvalidAPIKey, http.StatusUnauthorized, false},
+		{apikey.ErrBudgetExhausted, errcode.APIKeyQuotaExceeded, http.StatusTooManyRequests, true},
+		{fmt.Errorf("reserve: %w", budget.ErrExhausted), errcode.BudgetExhausted, http.StatusServiceUnavailable, true},
+		{fmt.Errorf("%w: entry 3 hash mismatch", audit.ErrTampered), errcode.AuditTampered, http.StatusConflict, false},
+		{errors.New("leveldb: closed"), errcode.Internal, http.StatusInternalServerError, false},
+	}
+	for _, c := range cases {
+		e := codeError(c.err)
+		require.Equal(t, c.code, e.Code, c.err.Error())
+		require.Equal(t, c.status, e.Code.Status())
+		require.Equal(t, c.retry, e.RetryAfter > 0, c.err.Error())
+		require.Contains(t, c.err.Error(), e.Msg)
+	}
+}
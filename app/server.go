@@ -1,17 +1,27 @@
 package app
 
 import (
-	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"faucet/internal"
+	"faucet/internal/acl"
+	"faucet/internal/budget"
+	"faucet/internal/captcha"
+	"faucet/internal/certs"
+	"faucet/internal/errcode"
+	"faucet/internal/i18n"
 	"faucet/internal/loggers"
+	"faucet/internal/openapi"
+	"faucet/internal/ownership"
 	"faucet/internal/utils"
 	"fmt"
+	"net"
 	"net/http"
-	"regexp"
 	"strings"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/sirupsen/logrus"
 )
 
@@ -23,14 +33,30 @@ type Server struct {
 	router *gin.Engine
 	logger logrus.FieldLogger
 	client *internal.Client
-
-	ctx    context.Context
-	cancel context.CancelFunc
+	// captcha 按 net 开启的人机验证
+	captcha map[string]captcha.Verifier
+	// challengers 按 net 开启的地址所有权校验
+	challengers map[string]*ownership.Challenger
+	limiters    *rateLimiters
+	ipResolver  *utils.IpResolver
+	spec        *openapi.Document
+	// reloaders 公网和 admin 端口使用的证书，Stop 时停止监听文件
+	reloaders []*certs.Reloader
+	// adminCAs 签发管理员客户端证书的 CA，只认它签发的证书，与监听端口校验过的证书链无关
+	adminCAs *x509.CertPool
+	// servers 公网、admin 和重定向端口上的 http 服务
+	servers []*http.Server
+	// node 注册了本服务的 Node，用于查询各服务状态
+	node *internal.Node
+	// draining 为 1 时进入排空阶段，新请求返回 503
+	draining int32
 }
 
 type nativeInput struct {
-	Net     string `json:"net"`
-	Address string `json:"address"`
+	Net          string `json:"net"`
+	Address      string `json:"address"`
+	CaptchaToken string `json:"captchaToken"`
+	Signature    string `json:"signature"`
 }
 
 type erc20Input struct {
@@ -39,99 +65,272 @@ type erc20Input struct {
 	ContractAddress string `json:"contractAddress"`
 }
 
-type response struct {
-	Msg  string `json:"msg"`
-	Data string `json:"txHash"`
+type dripData struct {
+	TxHash string `json:"txHash"`
+}
+
+type netInfo struct {
+	Net    string         `json:"net"`
+	Amount float64        `json:"amount"`
+	Paused bool           `json:"paused"`
+	Budget *budget.Status `json:"budget"`
+	// Balance 水龙头余额，链节点不可用时为空
+	Balance *float64 `json:"balance,omitempty"`
+	// Captcha 为空表示该 net 不需要人机验证，Ownership 表示需要先签名挑战
+	Captcha   *captchaInfo `json:"captcha,omitempty"`
+	Ownership bool         `json:"ownership"`
+	// Description 按请求语言生成的领取说明
+	Description string `json:"description"`
+}
+
+type captchaInfo struct {
+	Provider string `json:"provider"`
+	SiteKey  string `json:"siteKey"`
+}
+
+type infoData struct {
+	Networks []*netInfo `json:"networks"`
+}
+
+type challengeData struct {
+	Challenge string `json:"challenge"`
 }
 
 func NewServer(client *internal.Client) (*Server, error) {
-	ctx, cancel := context.WithCancel(context.Background())
+	verifiers := make(map[string]captcha.Verifier)
+	if client.Config.Axiom.Captcha.Enable {
+		verifier, err := captcha.New(client.Config.Axiom.Captcha)
+		if err != nil {
+			return nil, fmt.Errorf("init axm captcha: %w", err)
+		}
+		verifiers["axm"] = verifier
+	}
+	ipResolver, err := utils.NewIpResolver(client.Config.Network.TrustedProxies, client.Config.Network.ForwardedHeader)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := openapi.Load()
+	if err != nil {
+		return nil, err
+	}
+	challengers := make(map[string]*ownership.Challenger)
+	if client.Config.Axiom.Ownership.Enable {
+		challengers["axm"] = ownership.NewChallenger(client.Config.Axiom.Ownership.ChallengeTTL)
+	}
+
 	gin.SetMode(gin.ReleaseMode)
 	router := gin.New()
 	return &Server{
-		router: router,
-		client: client,
-		ctx:    ctx,
-		cancel: cancel,
-		logger: loggers.Logger(loggers.ApiServer),
+		router:      router,
+		client:      client,
+		captcha:     verifiers,
+		challengers: challengers,
+		limiters:    newRateLimiters(client.Config.RateLimit),
+		ipResolver:  ipResolver,
+		spec:        spec,
+		logger:      loggers.Logger(loggers.ApiServer),
 	}, nil
 }
 
 func (g *Server) Start() error {
-	g.router.Use(gin.Recovery()).Use(cors.Default()).Use(g.MaxAllowed(200))
-	v1 := g.router.Group("/faucet")
-	{
-		v1.POST("nativeToken", g.nativeToken)
+	g.router.Use(gin.Recovery()).Use(cors.Default()).Use(g.Drain()).Use(g.RateLimit())
+	g.router.GET("openapi.json", serveOpenAPI)
+	g.publicRoutes(g.router.Group("/faucet"))
+	if g.client.Config.UI.Enable {
+		if err := g.registerUI(); err != nil {
+			return err
+		}
 	}
-
-	go func() {
-		g.logger.Infoln("start gin success")
-		err := g.router.Run(fmt.Sprintf(":%s", g.client.Config.Network.Port))
+	if g.client.Config.Admin.Enable {
+		if err := g.registerAdmin(); err != nil {
+			return err
+		}
+	}
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%s", g.client.Config.Network.Port))
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	if g.client.Config.Network.ProxyProtocol {
+		ln = g.ipResolver.ProxyProtocolListener(ln)
+	}
+	// PROXY protocol 头在 tls 握手之前，所以 tls 包在最外层
+	if tlsCfg := g.client.Config.Network.TLS; tlsCfg.Enable {
+		tlsConfig, err := g.publicTLSConfig()
 		if err != nil {
-			panic(err)
+			ln.Close()
+			return err
 		}
-		<-g.ctx.Done()
-	}()
+		ln = tls.NewListener(ln, tlsConfig)
+		if tlsCfg.RedirectPort != "" {
+			if err := g.serveRedirect(); err != nil {
+				ln.Close()
+				return err
+			}
+		}
+	}
+
+	g.serve("faucet api", ln, g.router)
 	return nil
 }
 
+// publicRoutes 注册 /faucet 下的公开接口，接口定义见 internal/openapi/openapi.json
+func (g *Server) publicRoutes(v1 *gin.RouterGroup) {
+	v1.POST("nativeToken", g.Audit(), g.Validate(), g.AddressRateLimit(), g.APIKeyAuth(), g.CaptchaVerify(), g.nativeToken)
+	v1.GET("challenge", g.Validate(), g.challenge)
+	v1.GET("info", g.Validate(), g.info)
+	v1.GET("claim/:txHash", g.Validate(), g.claimStatus)
+}
+
+// clientIp 经可信代理解析出的客户端 ip
+func (g *Server) clientIp(c *gin.Context) string {
+	return g.ipResolver.ClientIp(c.Request)
+}
+
 func (g *Server) nativeToken(c *gin.Context) {
-	res := &response{}
 	var nativeInput nativeInput
-	if err := c.BindJSON(&nativeInput); err != nil {
-		res.Msg = err.Error()
-		c.JSON(http.StatusBadRequest, res)
+	if err := c.ShouldBindBodyWith(&nativeInput, binding.JSON); err != nil {
+		fail(c, errcode.Wrap(errcode.InvalidRequest, err))
 		return
 	}
 
-	if judge := IsValidEthereumAddress(nativeInput.Address); !judge {
-		res.Msg = fmt.Errorf("invalid address: %s", nativeInput.Address).Error()
-		c.JSON(http.StatusInternalServerError, res)
+	decision, err := g.authorizeDrip(nativeInput.Net, nativeInput.Address, nativeInput.Signature, g.clientIp(c))
+	if err != nil {
+		fail(c, err)
 		return
 	}
 
-	if !strings.EqualFold("axm", nativeInput.Net) {
-		res.Msg = fmt.Errorf("not support net: %s", nativeInput.Net).Error()
-		c.JSON(http.StatusInternalServerError, res)
+	req := &internal.DripRequest{
+		Net:          nativeInput.Net,
+		Address:      nativeInput.Address,
+		Source:       sourceAPI,
+		IP:           g.clientIp(c),
+		UserAgent:    c.Request.UserAgent(),
+		SkipCooldown: decision == acl.Allowed,
+	}
+	if key := requestAPIKey(c); key != nil {
+		req.APIKey = key
+		req.Tier, _ = g.client.Config.Axiom.Tier(key.Tier)
+	}
+
+	c.Set(auditedContextKey, true)
+	txHash, err := g.client.SendTra(req)
+	if err != nil {
+		fail(c, err)
 		return
 	}
+	ok(c, &dripData{TxHash: txHash})
+}
 
-	g.client.GinContext = c
-	data, err := g.client.SendTra(nativeInput.Net, nativeInput.Address)
+// authorizeDrip 校验地址所有权和黑白名单，http 和 gRPC 接口共用
+func (g *Server) authorizeDrip(net string, address string, signature string, ip string) (acl.Decision, error) {
+	if challenger, found := g.challengers[strings.ToLower(net)]; found {
+		if err := challenger.Verify(address, signature); err != nil {
+			return acl.None, err
+		}
+	}
+	decision := g.client.ACL.Check(address, ip)
+	if decision == acl.Denied {
+		return decision, errcode.New(errcode.Blocked, "address or ip is blocked")
+	}
+	return decision, nil
+}
+
+// info 各 net 的领取数量、暂停状态和剩余额度
+func (g *Server) info(c *gin.Context) {
+	res, err := g.faucetInfo(lang(c))
 	if err != nil {
-		res.Msg = err.Error()
-		c.JSON(http.StatusInternalServerError, res)
+		fail(c, err)
 		return
 	}
-	res.Msg = "ok"
-	res.Data = data
-	c.PureJSON(http.StatusOK, res)
+	ok(c, res)
 }
 
-func (g *Server) Stop() error {
-	g.client.Close()
-	g.cancel()
-	g.logger.Infoln("gin service stop")
-	return nil
+// faucetInfo 按语言 l 生成各 net 的领取说明，http 和 gRPC 接口共用
+func (g *Server) faucetInfo(l string) (*infoData, error) {
+	res := &infoData{}
+	for _, net := range supportedNets {
+		status, err := g.client.Budget(net).Status()
+		if err != nil {
+			return nil, err
+		}
+		info := &netInfo{
+			Net:    net,
+			Amount: g.client.Amount(net),
+			Paused: g.client.IsPaused(net),
+			Budget: status,
+		}
+		if balance, err := g.client.Balance(net); err != nil {
+			g.logger.Warnf("get faucet balance of %s: %s", net, err)
+		} else {
+			info.Balance = &balance
+		}
+		if _, found := g.captcha[net]; found {
+			cfg := g.client.Config.Axiom.Captcha
+			info.Captcha = &captchaInfo{Provider: strings.ToLower(cfg.Provider), SiteKey: cfg.SiteKey}
+		}
+		_, info.Ownership = g.challengers[net]
+		info.Description = i18n.Message(l, i18n.MsgNetDrip, info.Amount, net)
+		if info.Paused {
+			info.Description = i18n.Message(l, i18n.MsgNetPause, net)
+		}
+		res.Networks = append(res.Networks, info)
+	}
+	return res, nil
 }
 
-// MaxAllowed 限流器
-func (g *Server) MaxAllowed(limitValue int64) func(c *gin.Context) {
-	limiter := utils.NewLimiter(limitValue)
-	g.logger.Infof("limiter.SetMax: %d", limitValue)
-	// 返回限流逻辑
+// claimStatus 查询领取交易是否已经上链，供页面轮询
+func (g *Server) claimStatus(c *gin.Context) {
+	status, err := g.client.ClaimStatus(c.Param("txHash"))
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	ok(c, status)
+}
+
+// challenge 下发地址所有权挑战，客户端用 personal_sign 签名后随 nativeToken 请求提交
+func (g *Server) challenge(c *gin.Context) {
+	net := c.Query("net")
+	address := c.Query("address")
+
+	challenger, found := g.challengers[strings.ToLower(net)]
+	if !found {
+		fail(c, errcode.New(errcode.NotFound, "ownership proof not enabled for net: %s", net))
+		return
+	}
+	message, err := challenger.Issue(address)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	ok(c, &challengeData{Challenge: message})
+}
+
+// CaptchaVerify 人机验证，未开启验证的 net 和携带有效 api key 的调用方直接放行
+func (g *Server) CaptchaVerify() func(c *gin.Context) {
 	return func(c *gin.Context) {
-		if !limiter.Ok() {
-			c.AbortWithStatus(http.StatusServiceUnavailable) //超过每秒200，就返回503错误码
+		if requestAPIKey(c) != nil {
+			c.Next()
+			return
+		}
+		var input nativeInput
+		if err := c.ShouldBindBodyWith(&input, binding.JSON); err != nil {
+			fail(c, errcode.Wrap(errcode.InvalidRequest, err))
+			return
+		}
+		verifier, found := g.captcha[strings.ToLower(input.Net)]
+		if !found {
+			c.Next()
+			return
+		}
+		if err := verifier.Verify(c.Request.Context(), input.CaptchaToken, g.clientIp(c)); err != nil {
+			g.logger.Warnf("captcha verify for %s: %s", input.Address, err)
+			fail(c, err)
 			return
 		}
 		c.Next()
 	}
 }
 
-func IsValidEthereumAddress(address string) bool {
-	// 正则表达式模式匹配以太坊地址
-	pattern := "^0x[0-9a-fA-F]{40}$"
-	regex := regexp.MustCompile(pattern)
-	return regex.MatchString(address)
-}
+// supportedNets 目前只支持 axm
+var supportedNets = []string{"axm"}
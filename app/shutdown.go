@@ -0,0 +1,65 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"faucet/internal/errcode"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serve 在 ln 上启动 http 服务，Stop 时统一 Shutdown
+func (g *Server) serve(name string, ln net.Listener, handler http.Handler) {
+	srv := &http.Server{Addr: ln.Addr().String(), Handler: handler}
+	g.servers = append(g.servers, srv)
+	go func() {
+		g.logger.Infof("start %s on %s", name, srv.Addr)
+		if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			g.logger.Errorf("%s stopped: %s", name, err)
+		}
+	}()
+}
+
+// Drain 排空阶段拒绝新请求，并关闭连接让负载均衡尽快切走
+func (g *Server) Drain() func(c *gin.Context) {
+	return func(c *gin.Context) {
+		if atomic.LoadInt32(&g.draining) == 1 {
+			c.Header("Connection", "close")
+			fail(c, errcode.New(errcode.ShuttingDown, "server is shutting down"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// Stop 先进入排空阶段：新请求返回 503，等待进行中的发放结束，超时后放弃等待交易确认，
+// 未确认的交易保留在 store 中；随后关闭 http 服务。store 和链上节点连接由 Node 在最后关闭
+func (g *Server) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), g.client.Config.Shutdown.Timeout)
+	defer cancel()
+
+	atomic.StoreInt32(&g.draining, 1)
+	for _, srv := range g.servers {
+		srv.SetKeepAlivesEnabled(false)
+	}
+	if err := g.client.Drain(ctx); err != nil {
+		g.logger.Warnf("drain in-flight drips: %s", err)
+	}
+	for _, srv := range g.servers {
+		if err := srv.Shutdown(ctx); err != nil {
+			g.logger.Warnf("shutdown http server on %s: %s", srv.Addr, err)
+			srv.Close()
+		}
+	}
+
+	for _, reloader := range g.reloaders {
+		if err := reloader.Close(); err != nil {
+			g.logger.Errorf("stop tls cert reloader: %s", err)
+		}
+	}
+	g.logger.Infoln("gin service stop")
+	return nil
+}
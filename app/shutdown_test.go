@@ -0,0 +1,32 @@
+package app
+
+import (
+	"encoding/json"
+	"faucet/internal/errcode"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrainMiddleware(t *testing.T) {
+	g := &Server{}
+	router := gin.New()
+	router.Use(g.Drain())
+	router.GET("/faucet/info", func(c *gin.Context) { ok(c, nil) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/faucet/info", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	g.draining = 1
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/faucet/info", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, "close", w.Header().Get("Connection"))
+	var res envelope
+	require.Nil(t, json.Unmarshal(w.Body.Bytes(), &res))
+	require.Equal(t, errcode.ShuttingDown, res.Code)
+}
@@ -0,0 +1,65 @@
+package app
+
+import (
+	"crypto/tls"
+	"faucet/internal/certs"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+)
+
+// loadCert 加载 repo 目录下的证书，证书文件变化后自动重新加载，Stop 时关闭
+func (g *Server) loadCert(certFile, keyFile string) (*certs.Reloader, error) {
+	root := g.client.Config.RepoRoot
+	reloader, err := certs.NewReloader(filepath.Join(root, certFile), filepath.Join(root, keyFile), g.logger)
+	if err != nil {
+		return nil, err
+	}
+	g.reloaders = append(g.reloaders, reloader)
+	return reloader, nil
+}
+
+// publicTLSConfig /admin 挂在公网端口且配置了 admin.client_ca 时，只校验客户端主动出示的证书，普通用户不受影响
+func (g *Server) publicTLSConfig() (*tls.Config, error) {
+	cfg := g.client.Config.Network.TLS
+	reloader, err := g.loadCert(cfg.Cert, cfg.Key)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := reloader.TLSConfig()
+	if admin := g.client.Config.Admin; admin.Enable && admin.Port == "" && g.adminCAs != nil {
+		tlsConfig.ClientCAs = g.adminCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
+
+// serveRedirect 在 redirect_port 上监听 http，把请求重定向到公网 https 端口
+func (g *Server) serveRedirect() error {
+	port := g.client.Config.Network.TLS.RedirectPort
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
+	if err != nil {
+		return fmt.Errorf("redirect listen: %w", err)
+	}
+	g.serve("https redirect", ln, redirectHandler(g.client.Config.Network.Port))
+	return nil
+}
+
+// redirectHandler 保留请求的主机名、路径和参数，308 让客户端用原方法和请求体重发
+func redirectHandler(httpsPort string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+		if httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]"
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
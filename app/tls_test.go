@@ -0,0 +1,32 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedirectHandler(t *testing.T) {
+	cases := []struct {
+		port     string
+		host     string
+		target   string
+		location string
+	}{
+		{"8443", "faucet.example.com", "/faucet/info?lang=zh", "https://faucet.example.com:8443/faucet/info?lang=zh"},
+		{"8443", "faucet.example.com:8080", "/", "https://faucet.example.com:8443/"},
+		{"443", "faucet.example.com:80", "/faucet/claim/0x01", "https://faucet.example.com/faucet/claim/0x01"},
+		{"443", "[::1]:80", "/", "https://[::1]/"},
+		{"8443", "[::1]", "/", "https://[::1]:8443/"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, c.target, nil)
+		req.Host = c.host
+		w := httptest.NewRecorder()
+		redirectHandler(c.port).ServeHTTP(w, req)
+		require.Equal(t, http.StatusPermanentRedirect, w.Code)
+		require.Equal(t, c.location, w.Header().Get("Location"), c.host)
+	}
+}
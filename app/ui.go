@@ -0,0 +1,31 @@
+package app
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// web 内置的领取页面，页面只调用 /faucet 下的公开接口
+//
+//go:embed web
+var web embed.FS
+
+// registerUI 在 / 提供领取页面，静态资源挂在 /static 下
+func (g *Server) registerUI() error {
+	static, err := fs.Sub(web, "web")
+	if err != nil {
+		return err
+	}
+	index, err := fs.ReadFile(static, "index.html")
+	if err != nil {
+		return err
+	}
+	g.router.GET("/", func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", index)
+	})
+	g.router.StaticFS("/static", http.FS(static))
+	return nil
+}
@@ -0,0 +1,216 @@
+// 领取页面：展示 /faucet/info，提交 /faucet/nativeToken，并轮询 /faucet/claim/:txHash 直到交易上链。
+//
+// 部署方可以在加载本脚本之前定义 window.faucetHooks 接入额外的校验，例如工作量证明：
+//   beforeClaim({net, address, body, headers}) 返回（或 resolve）要提交的 {body, headers}
+//   captchaToken(provider) 返回验证组件的 token，替换内置的 hCaptcha/reCAPTCHA/Turnstile 读取方式
+(function () {
+  'use strict';
+
+  var hooks = window.faucetHooks || {};
+  var lang = (navigator.language || 'en').toLowerCase().indexOf('zh') === 0 ? 'zh' : 'en';
+  var text = {
+    en: {
+      title: 'Testnet Faucet', networks: 'Networks', network: 'Network', amount: 'Amount',
+      balance: 'Faucet balance', status: 'Status', claim: 'Claim', address: 'Address',
+      submit: 'Request tokens', tx: 'Transaction', active: 'active', paused: 'paused',
+      unconfirmed: 'waiting for confirmation', confirmed: 'confirmed', failed: 'failed',
+      sign: 'Please sign the challenge in your wallet', noWallet: 'A wallet is required to prove address ownership'
+    },
+    zh: {
+      title: '测试网水龙头', networks: '网络', network: '网络', amount: '每次数量',
+      balance: '水龙头余额', status: '状态', claim: '领取', address: '地址',
+      submit: '领取测试币', tx: '交易', active: '正常', paused: '已暂停',
+      unconfirmed: '等待确认', confirmed: '已确认', failed: '失败',
+      sign: '请在钱包中签名挑战', noWallet: '需要钱包来证明地址所有权'
+    }
+  }[lang];
+
+  var captchaScripts = {
+    hcaptcha: { src: 'https://js.hcaptcha.com/1/api.js', global: 'hcaptcha' },
+    recaptcha: { src: 'https://www.google.com/recaptcha/api.js', global: 'grecaptcha' },
+    turnstile: { src: 'https://challenges.cloudflare.com/turnstile/v0/api.js', global: 'turnstile' }
+  };
+
+  var networks = {};
+  var form = document.getElementById('claim');
+  var message = document.getElementById('message');
+  var tx = document.getElementById('tx');
+  var pollTimer = null;
+
+  // api 调用 faucet 接口，响应为 {apiVersion, code, msg, data}，code 不为 OK 时抛出 msg
+  function api(method, path, body, headers) {
+    var init = { method: method, headers: Object.assign({ 'Accept-Language': lang }, headers || {}) };
+    if (body !== undefined) {
+      init.headers['Content-Type'] = 'application/json';
+      init.body = JSON.stringify(body);
+    }
+    return fetch(path, init).then(function (res) {
+      return res.json().then(function (envelope) {
+        if (envelope.code !== 'OK') {
+          var err = new Error(envelope.msg);
+          err.code = envelope.code;
+          err.retryAfter = envelope.retryAfter;
+          throw err;
+        }
+        return envelope.data;
+      });
+    });
+  }
+
+  function show(msg, isError) {
+    message.textContent = msg || '';
+    message.className = isError ? 'error' : '';
+  }
+
+  function translate() {
+    document.documentElement.lang = lang;
+    document.title = text.title;
+    Array.prototype.forEach.call(document.querySelectorAll('[data-i18n]'), function (el) {
+      el.textContent = text[el.getAttribute('data-i18n')];
+    });
+  }
+
+  function loadInfo() {
+    return api('GET', '/faucet/info').then(function (data) {
+      var tbody = document.querySelector('#networks tbody');
+      var select = form.elements.net;
+      var selected = select.value;
+      tbody.innerHTML = '';
+      select.innerHTML = '';
+      data.networks.forEach(function (info) {
+        networks[info.net] = info;
+        var row = tbody.insertRow();
+        row.insertCell().textContent = info.net;
+        row.insertCell().textContent = info.amount;
+        row.insertCell().textContent = info.balance === undefined ? '-' : info.balance.toFixed(4);
+        var state = row.insertCell();
+        state.textContent = info.paused ? text.paused : text.active;
+        state.title = info.description;
+        if (info.paused) {
+          state.className = 'paused';
+        }
+        select.add(new Option(info.net, info.net, false, info.net === selected));
+      });
+      renderCaptcha();
+    }).catch(function (err) {
+      show(err.message, true);
+    });
+  }
+
+  function renderCaptcha() {
+    var info = networks[form.elements.net.value];
+    var container = document.getElementById('captcha');
+    container.innerHTML = '';
+    if (!info || !info.captcha || !captchaScripts[info.captcha.provider]) {
+      return;
+    }
+    var script = captchaScripts[info.captcha.provider];
+    var widget = document.createElement('div');
+    container.appendChild(widget);
+    var render = function () {
+      window[script.global].render(widget, { sitekey: info.captcha.siteKey });
+    };
+    if (window[script.global] && window[script.global].render) {
+      render();
+      return;
+    }
+    window.faucetCaptchaLoaded = render;
+    var tag = document.createElement('script');
+    tag.src = script.src + '?onload=faucetCaptchaLoaded&render=explicit';
+    tag.async = true;
+    document.head.appendChild(tag);
+  }
+
+  function captchaToken(info) {
+    if (!info.captcha) {
+      return Promise.resolve('');
+    }
+    if (hooks.captchaToken) {
+      return Promise.resolve(hooks.captchaToken(info.captcha.provider));
+    }
+    var widget = window[captchaScripts[info.captcha.provider].global];
+    return Promise.resolve(widget ? widget.getResponse() : '');
+  }
+
+  function resetCaptcha(info) {
+    var widget = info.captcha && window[captchaScripts[info.captcha.provider].global];
+    if (widget && widget.reset) {
+      widget.reset();
+    }
+  }
+
+  // ownershipSignature 开启所有权校验时，用钱包对服务端下发的挑战做 personal_sign
+  function ownershipSignature(info, address) {
+    if (!info.ownership) {
+      return Promise.resolve('');
+    }
+    if (!window.ethereum) {
+      return Promise.reject(new Error(text.noWallet));
+    }
+    var query = '?net=' + encodeURIComponent(info.net) + '&address=' + encodeURIComponent(address);
+    return api('GET', '/faucet/challenge' + query).then(function (data) {
+      show(text.sign);
+      return window.ethereum.request({ method: 'eth_requestAccounts' }).then(function () {
+        return window.ethereum.request({ method: 'personal_sign', params: [data.challenge, address] });
+      });
+    });
+  }
+
+  function poll(txHash) {
+    clearTimeout(pollTimer);
+    tx.hidden = false;
+    tx.querySelector('code').textContent = txHash;
+    var state = tx.querySelector('.state');
+    state.textContent = text.unconfirmed;
+    var check = function () {
+      api('GET', '/faucet/claim/' + txHash).then(function (status) {
+        state.textContent = text[status.status] || status.status;
+        if (status.status === 'unconfirmed') {
+          pollTimer = setTimeout(check, 3000);
+        } else {
+          loadInfo();
+        }
+      }).catch(function (err) {
+        if (err.code === 'NOT_FOUND' || err.code === 'CHAIN_UNAVAILABLE') {
+          pollTimer = setTimeout(check, 3000);
+          return;
+        }
+        state.textContent = err.message;
+      });
+    };
+    check();
+  }
+
+  form.addEventListener('submit', function (event) {
+    event.preventDefault();
+    var info = networks[form.elements.net.value];
+    var address = form.elements.address.value.trim();
+    if (!info) {
+      return;
+    }
+    var button = form.querySelector('button');
+    button.disabled = true;
+    show('');
+    var body = { net: info.net, address: address };
+    Promise.all([captchaToken(info), ownershipSignature(info, address)]).then(function (proofs) {
+      body.captchaToken = proofs[0];
+      body.signature = proofs[1];
+      var claim = { net: info.net, address: address, body: body, headers: {} };
+      return Promise.resolve(hooks.beforeClaim ? hooks.beforeClaim(claim) : claim);
+    }).then(function (claim) {
+      return api('POST', '/faucet/nativeToken', claim.body, claim.headers);
+    }).then(function (data) {
+      show('');
+      poll(data.txHash);
+    }).catch(function (err) {
+      show(err.message, true);
+    }).then(function () {
+      button.disabled = false;
+      resetCaptcha(info);
+    });
+  });
+
+  form.elements.net.addEventListener('change', renderCaptcha);
+  translate();
+  loadInfo();
+})();
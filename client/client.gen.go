@@ -0,0 +1,362 @@
+// Code generated by internal/openapi/gen from openapi.json. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+)
+
+type ACLData struct {
+	Entries []*ACLEntry `json:"entries,omitempty"`
+}
+
+type ACLEntry struct {
+	Comment   string `json:"comment"`
+	CreatedAt int64  `json:"createdAt"`
+	Kind      string `json:"kind"`
+	List      string `json:"list"`
+	Value     string `json:"value"`
+}
+
+type AuditData struct {
+	Entries []*AuditEntry `json:"entries,omitempty"`
+}
+
+type AuditEntry struct {
+	Address  string  `json:"address"`
+	Amount   float64 `json:"amount"`
+	APIKey   string  `json:"apiKey"`
+	Decision string  `json:"decision"`
+	GasUsed  uint64  `json:"gasUsed"`
+	Hash     string  `json:"hash"`
+	IP       string  `json:"ip"`
+	Net      string  `json:"net"`
+	PrevHash string  `json:"prevHash"`
+	Reason   string  `json:"reason"`
+	Seq      uint64  `json:"seq"`
+	Source   string  `json:"source"`
+	Status   string  `json:"status"`
+	// Time Unix nanoseconds
+	Time      int64  `json:"time"`
+	TxHash    string `json:"txHash"`
+	UserAgent string `json:"userAgent"`
+}
+
+type BackupData struct {
+	Path    string `json:"path"`
+	Records int    `json:"records"`
+}
+
+type BudgetStatus struct {
+	Daily           float64 `json:"daily"`
+	DailyRemaining  float64 `json:"dailyRemaining"`
+	Hourly          float64 `json:"hourly"`
+	HourlyRemaining float64 `json:"hourlyRemaining"`
+}
+
+type CaptchaInfo struct {
+	Provider string `json:"provider"`
+	SiteKey  string `json:"siteKey"`
+}
+
+type ChallengeData struct {
+	// Challenge Message to sign with personal_sign
+	Challenge string `json:"challenge"`
+}
+
+type ClaimStatus struct {
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+	Status      string `json:"status"`
+	TxHash      string `json:"txHash"`
+}
+
+type DripData struct {
+	TxHash string `json:"txHash"`
+}
+
+type Envelope struct {
+	APIVersion string `json:"apiVersion"`
+	// Code OK or an error code
+	Code string `json:"code"`
+	// Data Operation specific payload
+	Data json.RawMessage `json:"data,omitempty"`
+	// Detail Untranslated error detail
+	Detail string `json:"detail,omitempty"`
+	// Msg Localized message
+	Msg string `json:"msg"`
+	// RetryAfter Seconds to wait before retrying
+	RetryAfter int `json:"retryAfter,omitempty"`
+}
+
+type InfoData struct {
+	Networks []*NetInfo `json:"networks"`
+}
+
+type NetInfo struct {
+	Amount float64 `json:"amount"`
+	// Balance Faucet balance, absent when the chain node is unavailable
+	Balance     float64       `json:"balance,omitempty"`
+	Budget      *BudgetStatus `json:"budget"`
+	Captcha     *CaptchaInfo  `json:"captcha,omitempty"`
+	Description string        `json:"description"`
+	Net         string        `json:"net"`
+	Ownership   bool          `json:"ownership"`
+	Paused      bool          `json:"paused"`
+}
+
+type PendingData struct {
+	Txs []*PendingTx `json:"txs,omitempty"`
+}
+
+type PendingTx struct {
+	Address string  `json:"address"`
+	Amount  float64 `json:"amount"`
+	Net     string  `json:"net"`
+	SentAt  int64   `json:"sentAt"`
+	TxHash  string  `json:"txHash"`
+}
+
+type ServiceInfo struct {
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Error Why the service failed to start or stop
+	Error  string `json:"error,omitempty"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+}
+
+type ServicesData struct {
+	Services []*ServiceInfo `json:"services"`
+}
+
+type VerifyData struct {
+	Verified int `json:"verified"`
+}
+
+type ACLInput struct {
+	Comment string `json:"comment,omitempty"`
+	List    string `json:"list"`
+	// Value Address, ip or CIDR
+	Value string `json:"value"`
+}
+
+// AddACL Add an address, ip or CIDR to a list
+func (c *Client) AddACL(ctx context.Context, body *ACLInput) (*ACLData, error) {
+	out := &ACLData{}
+	if err := c.do(ctx, "POST", "/admin/acl", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Backup Write an online backup of the store under the repo backups directory
+func (c *Client) Backup(ctx context.Context) (*BackupData, error) {
+	out := &BackupData{}
+	if err := c.do(ctx, "POST", "/admin/backup", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetChallengeParams are the query parameters of GetChallenge
+type GetChallengeParams struct {
+	Net     string
+	Address string
+}
+
+// GetChallenge Issue an address ownership challenge
+func (c *Client) GetChallenge(ctx context.Context, params *GetChallengeParams) (*ChallengeData, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.Net != "" {
+			query.Set("net", params.Net)
+		}
+		if params.Address != "" {
+			query.Set("address", params.Address)
+		}
+	}
+	out := &ChallengeData{}
+	if err := c.do(ctx, "GET", "/faucet/challenge", query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetClaimStatus Confirmation status of a claim transaction sent by this faucet
+func (c *Client) GetClaimStatus(ctx context.Context, txHash string) (*ClaimStatus, error) {
+	out := &ClaimStatus{}
+	if err := c.do(ctx, "GET", "/faucet/claim/"+url.PathEscape(txHash), nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetInfo Supported networks, amounts, budgets and faucet balance
+func (c *Client) GetInfo(ctx context.Context) (*InfoData, error) {
+	out := &InfoData{}
+	if err := c.do(ctx, "GET", "/faucet/info", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListACLParams are the query parameters of ListACL
+type ListACLParams struct {
+	List string
+}
+
+// ListACL List allow and deny list entries
+func (c *Client) ListACL(ctx context.Context, params *ListACLParams) (*ACLData, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.List != "" {
+			query.Set("list", params.List)
+		}
+	}
+	out := &ACLData{}
+	if err := c.do(ctx, "GET", "/admin/acl", query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListAuditParams are the query parameters of ListAudit
+type ListAuditParams struct {
+	// From RFC3339 time or 2006-01-02 date
+	From string
+	// To RFC3339 time or 2006-01-02 date
+	To string
+	// Format csv downloads the entries as a CSV file
+	Format string
+}
+
+// ListAudit Audit ledger entries within [from, to)
+func (c *Client) ListAudit(ctx context.Context, params *ListAuditParams) (*AuditData, error) {
+	query := url.Values{}
+	if params != nil {
+		if params.From != "" {
+			query.Set("from", params.From)
+		}
+		if params.To != "" {
+			query.Set("to", params.To)
+		}
+		if params.Format != "" {
+			query.Set("format", params.Format)
+		}
+	}
+	out := &AuditData{}
+	if err := c.do(ctx, "GET", "/admin/audit", query, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListPending Broadcast claim transactions waiting for confirmation
+func (c *Client) ListPending(ctx context.Context) (*PendingData, error) {
+	out := &PendingData{}
+	if err := c.do(ctx, "GET", "/admin/pending", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListServices Status of every registered faucet service
+func (c *Client) ListServices(ctx context.Context) (*ServicesData, error) {
+	out := &ServicesData{}
+	if err := c.do(ctx, "GET", "/admin/services", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type ManualSendRequest struct {
+	Address      string `json:"address"`
+	Net          string `json:"net"`
+	SkipCooldown bool   `json:"skipCooldown,omitempty"`
+}
+
+// ManualSend Send test tokens without captcha and acl checks
+func (c *Client) ManualSend(ctx context.Context, body *ManualSendRequest) (*DripData, error) {
+	out := &DripData{}
+	if err := c.do(ctx, "POST", "/admin/send", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type NetInput struct {
+	Net string `json:"net"`
+}
+
+// Pause Pause claims on a net
+func (c *Client) Pause(ctx context.Context, body *NetInput) error {
+	return c.do(ctx, "POST", "/admin/pause", nil, body, nil)
+}
+
+// RemoveACL Remove an address, ip or CIDR from a list
+func (c *Client) RemoveACL(ctx context.Context, body *ACLInput) error {
+	return c.do(ctx, "DELETE", "/admin/acl", nil, body, nil)
+}
+
+type RequestDripRequest struct {
+	Address string `json:"address"`
+	// CaptchaToken Token of the captcha widget, required when the net enables captcha
+	CaptchaToken string `json:"captchaToken,omitempty"`
+	Net          string `json:"net"`
+	// Signature personal_sign signature of the challenge, required when the net enables ownership proof
+	Signature string `json:"signature,omitempty"`
+}
+
+// RequestDrip Request native test tokens
+func (c *Client) RequestDrip(ctx context.Context, body *RequestDripRequest) (*DripData, error) {
+	out := &DripData{}
+	if err := c.do(ctx, "POST", "/faucet/nativeToken", nil, body, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ResetCooldownParams are the query parameters of ResetCooldown
+type ResetCooldownParams struct {
+	Net     string
+	Address string
+}
+
+// ResetCooldown Delete the claim records of an address so it can claim again
+func (c *Client) ResetCooldown(ctx context.Context, params *ResetCooldownParams) error {
+	query := url.Values{}
+	if params != nil {
+		if params.Net != "" {
+			query.Set("net", params.Net)
+		}
+		if params.Address != "" {
+			query.Set("address", params.Address)
+		}
+	}
+	return c.do(ctx, "DELETE", "/admin/cooldown", query, nil, nil)
+}
+
+// Resume Resume claims on a net
+func (c *Client) Resume(ctx context.Context, body *NetInput) error {
+	return c.do(ctx, "POST", "/admin/resume", nil, body, nil)
+}
+
+type SetAmountRequest struct {
+	Amount float64 `json:"amount"`
+	Net    string  `json:"net"`
+}
+
+// SetAmount Change the claim amount of a net
+func (c *Client) SetAmount(ctx context.Context, body *SetAmountRequest) error {
+	return c.do(ctx, "PUT", "/admin/amount", nil, body, nil)
+}
+
+// VerifyAudit Verify the hash chain of the audit ledger
+func (c *Client) VerifyAudit(ctx context.Context) (*VerifyData, error) {
+	out := &VerifyData{}
+	if err := c.do(ctx, "GET", "/admin/audit/verify", nil, nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,134 @@
+// Package client 是 faucet http api 的 Go 客户端，接口方法和类型由 internal/openapi/openapi.json 生成
+package client
+
+//go:generate go run ../internal/openapi/gen -o client.gen.go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// codeOK 成功响应的错误码
+const codeOK = "OK"
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	header     http.Header
+}
+
+type Option func(c *Client)
+
+// WithHTTPClient 替换默认的 http.Client，例如配置超时或 mTLS
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithAPIKey 通过 X-Api-Key 携带 api key，按 key 所属 tier 的配额领取
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.header.Set("X-Api-Key", key)
+	}
+}
+
+// WithAdminToken 调用 /admin 接口使用的 token
+func WithAdminToken(token string) Option {
+	return func(c *Client) {
+		c.header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithLanguage 错误提示的语言，例如 en、zh
+func WithLanguage(lang string) Option {
+	return func(c *Client) {
+		c.header.Set("Accept-Language", lang)
+	}
+}
+
+// New 创建客户端，baseURL 形如 http://localhost:8080
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		header:     make(http.Header),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error 接口返回的错误，客户端应当按 Code 判断错误类型
+type Error struct {
+	StatusCode int
+	Code       string
+	Msg        string
+	Detail     string
+	// RetryAfter 大于 0 时表示多久之后可以重试
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Detail)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Msg)
+}
+
+// do 发送请求并把响应 data 解析到 out，out 为 nil 时忽略 data
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, body interface{}, out interface{}) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	envelope := &Envelope{}
+	if err := json.NewDecoder(res.Body).Decode(envelope); err != nil {
+		return fmt.Errorf("decode %s %s response with status %d: %w", method, path, res.StatusCode, err)
+	}
+	if envelope.Code != codeOK {
+		return &Error{
+			StatusCode: res.StatusCode,
+			Code:       envelope.Code,
+			Msg:        envelope.Msg,
+			Detail:     envelope.Detail,
+			RetryAfter: time.Duration(envelope.RetryAfter) * time.Second,
+		}
+	}
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestDrip(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/faucet/nativeToken", r.URL.Path)
+		require.Equal(t, "key", r.Header.Get("X-Api-Key"))
+		require.Equal(t, "zh", r.Header.Get("Accept-Language"))
+		var body RequestDripRequest
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		if body.Address == "0x0000000000000000000000000000000000000001" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"apiVersion":"v1","code":"COOLDOWN_ACTIVE","msg":"该地址近期已领取过测试币","detail":"cooldown","retryAfter":30}`))
+			return
+		}
+		w.Write([]byte(`{"apiVersion":"v1","code":"OK","msg":"成功","data":{"txHash":"0xabc"}}`))
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL+"/", WithAPIKey("key"), WithLanguage("zh"))
+	data, err := c.RequestDrip(context.Background(), &RequestDripRequest{Net: "axm", Address: "0x0000000000000000000000000000000000000002"})
+	require.Nil(t, err)
+	require.Equal(t, "0xabc", data.TxHash)
+
+	_, err = c.RequestDrip(context.Background(), &RequestDripRequest{Net: "axm", Address: "0x0000000000000000000000000000000000000001"})
+	e, ok := err.(*Error)
+	require.True(t, ok)
+	require.Equal(t, http.StatusTooManyRequests, e.StatusCode)
+	require.Equal(t, "COOLDOWN_ACTIVE", e.Code)
+	require.Equal(t, 30*time.Second, e.RetryAfter)
+}
+
+func TestQueryAndPathParams(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/admin/cooldown":
+			require.Equal(t, http.MethodDelete, r.Method)
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			require.Equal(t, "axm", r.URL.Query().Get("net"))
+			w.Write([]byte(`{"apiVersion":"v1","code":"OK","msg":"ok"}`))
+		case "/faucet/claim/0xabc":
+			w.Write([]byte(`{"apiVersion":"v1","code":"OK","msg":"ok","data":{"txHash":"0xabc","status":"confirmed","blockNumber":7}}`))
+		default:
+			t.Fatalf("unexpected path %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithAdminToken("token"))
+	require.Nil(t, c.ResetCooldown(context.Background(), &ResetCooldownParams{Net: "axm", Address: "0x1"}))
+	status, err := c.GetClaimStatus(context.Background(), "0xabc")
+	require.Nil(t, err)
+	require.Equal(t, uint64(7), status.BlockNumber)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"faucet/internal/acl"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+// aclDescription acl 命令直接打开 store，运行中的 faucet 持有 leveldb 的锁，名单也只在启动时加载
+const aclDescription = `The faucet must be stopped first: the command opens the store directly.
+   Changes take effect when the faucet starts again. To change the lists of a
+   running faucet, use the /admin/acl api instead.`
+
+var aclCMD = cli.Command{
+	Name:  "acl",
+	Usage: "Manage address and ip allow/deny lists of a stopped faucet",
+	Subcommands: []cli.Command{
+		{
+			Name:        "add",
+			Usage:       "Add an address, ip or cidr to the allow or deny list",
+			Description: aclDescription,
+			ArgsUsage:   "<allow|deny> <address|ip|cidr>",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "comment",
+					Usage: "Why the entry was added",
+				},
+			},
+			Action: aclAdd,
+		},
+		{
+			Name:        "remove",
+			Usage:       "Remove an address, ip or cidr from the allow or deny list",
+			Description: aclDescription,
+			ArgsUsage:   "<allow|deny> <address|ip|cidr>",
+			Action:      aclRemove,
+		},
+		{
+			Name:        "list",
+			Usage:       "List allow and deny entries",
+			Description: aclDescription,
+			ArgsUsage:   "[allow|deny]",
+			Action:      aclList,
+		},
+	},
+}
+
+func aclAdd(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return fmt.Errorf("usage: faucet acl add <allow|deny> <address|ip|cidr>")
+	}
+	return withACL(ctx, func(a *acl.ACL) error {
+		entry, err := a.Add(ctx.Args().Get(0), ctx.Args().Get(1), ctx.String("comment"))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("added %s %s to %s list\n", entry.Kind, entry.Value, entry.List)
+		return nil
+	})
+}
+
+func aclRemove(ctx *cli.Context) error {
+	if ctx.NArg() != 2 {
+		return fmt.Errorf("usage: faucet acl remove <allow|deny> <address|ip|cidr>")
+	}
+	return withACL(ctx, func(a *acl.ACL) error {
+		if err := a.Remove(ctx.Args().Get(0), ctx.Args().Get(1)); err != nil {
+			return err
+		}
+		fmt.Printf("removed %s from %s list\n", ctx.Args().Get(1), ctx.Args().Get(0))
+		return nil
+	})
+}
+
+func aclList(ctx *cli.Context) error {
+	return withACL(ctx, func(a *acl.ACL) error {
+		for _, entry := range a.Entries(ctx.Args().First()) {
+			fmt.Printf("%-6s %-8s %-44s %s %s\n", entry.List, entry.Kind, entry.Value,
+				time.Unix(entry.CreatedAt, 0).Format("2006-01-02 15:04:05"), entry.Comment)
+		}
+		return nil
+	})
+}
+
+func withACL(ctx *cli.Context, fn func(a *acl.ACL) error) error {
+	s, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	a, err := acl.New(s.ACLs())
+	if err != nil {
+		return err
+	}
+	return fn(a)
+}
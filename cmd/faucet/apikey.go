@@ -0,0 +1,110 @@
+package main
+
+import (
+	"faucet/internal/apikey"
+	"faucet/internal/repo"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli"
+)
+
+var apikeyCMD = cli.Command{
+	Name:  "apikey",
+	Usage: "Manage api keys of trusted integrators",
+	Subcommands: []cli.Command{
+		{
+			Name:  "issue",
+			Usage: "Issue a new api key bound to a tier",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:     "name",
+					Usage:    "Who the key is issued to",
+					Required: true,
+				},
+				cli.StringFlag{
+					Name:     "tier",
+					Usage:    "Tier name configured in faucet.toml",
+					Required: true,
+				},
+			},
+			Action: apikeyIssue,
+		},
+		{
+			Name:   "list",
+			Usage:  "List issued api keys",
+			Action: apikeyList,
+		},
+		{
+			Name:      "revoke",
+			Usage:     "Revoke an api key",
+			ArgsUsage: "<id>",
+			Action:    apikeyRevoke,
+		},
+	},
+}
+
+func apikeyIssue(ctx *cli.Context) error {
+	repoRoot, err := repo.PathRootWithDefault(ctx.GlobalString("repo"))
+	if err != nil {
+		return err
+	}
+	config, err := repo.UnmarshalConfig(repoRoot)
+	if err != nil {
+		return fmt.Errorf("init config error: %s", err)
+	}
+	if _, ok := config.Axiom.Tier(ctx.String("tier")); !ok {
+		return fmt.Errorf("tier %s is not configured", ctx.String("tier"))
+	}
+
+	return withAPIKeys(ctx, func(s *apikey.Store) error {
+		secret, key, err := s.Issue(ctx.String("name"), ctx.String("tier"))
+		if err != nil {
+			return err
+		}
+		fmt.Printf("issued api key %s for %s (tier %s)\n", key.ID, key.Name, key.Tier)
+		fmt.Printf("%s\n", secret)
+		fmt.Println("the key is only shown once, store it safely")
+		return nil
+	})
+}
+
+func apikeyList(ctx *cli.Context) error {
+	return withAPIKeys(ctx, func(s *apikey.Store) error {
+		keys, err := s.List()
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			status := "active"
+			if key.Revoked {
+				status = "revoked"
+			}
+			fmt.Printf("%-12s %-10s %-8s %s %s\n", key.ID, key.Tier, status,
+				time.Unix(key.CreatedAt, 0).Format("2006-01-02 15:04:05"), key.Name)
+		}
+		return nil
+	})
+}
+
+func apikeyRevoke(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("usage: faucet apikey revoke <id>")
+	}
+	return withAPIKeys(ctx, func(s *apikey.Store) error {
+		if err := s.Revoke(ctx.Args().First()); err != nil {
+			return err
+		}
+		fmt.Printf("revoked api key %s\n", ctx.Args().First())
+		return nil
+	})
+}
+
+func withAPIKeys(ctx *cli.Context, fn func(s *apikey.Store) error) error {
+	s, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return fn(apikey.New(s.APIKeys()))
+}
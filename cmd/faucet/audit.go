@@ -0,0 +1,82 @@
+package main
+
+import (
+	"faucet/internal/audit"
+	"fmt"
+	"os"
+
+	"github.com/urfave/cli"
+)
+
+var auditCMD = cli.Command{
+	Name:  "audit",
+	Usage: "Inspect the audit ledger of dispense attempts",
+	Subcommands: []cli.Command{
+		{
+			Name:  "export",
+			Usage: "Export audit entries within a time range as CSV",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "from",
+					Usage: "Start time in RFC3339 or 2006-01-02, default to the first entry",
+				},
+				cli.StringFlag{
+					Name:  "to",
+					Usage: "End time (exclusive) in RFC3339 or 2006-01-02, default to now",
+				},
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "Output file, default to stdout",
+				},
+			},
+			Action: auditExport,
+		},
+		{
+			Name:   "verify",
+			Usage:  "Verify the hash chain of the audit ledger",
+			Action: auditVerify,
+		},
+	},
+}
+
+func auditExport(ctx *cli.Context) error {
+	from, to, err := audit.ParseTimeRange(ctx.String("from"), ctx.String("to"))
+	if err != nil {
+		return err
+	}
+	return withLedger(ctx, func(l *audit.Ledger) error {
+		entries, err := l.Range(from, to)
+		if err != nil {
+			return err
+		}
+		out := os.Stdout
+		if output := ctx.String("output"); output != "" {
+			out, err = os.Create(output)
+			if err != nil {
+				return err
+			}
+			defer out.Close()
+		}
+		return audit.WriteCSV(out, entries)
+	})
+}
+
+func auditVerify(ctx *cli.Context) error {
+	return withLedger(ctx, func(l *audit.Ledger) error {
+		count, err := l.Verify()
+		if err != nil {
+			return fmt.Errorf("%d entries verified: %w", count, err)
+		}
+		fmt.Printf("audit ledger intact, %d entries verified\n", count)
+		return nil
+	})
+}
+
+func withLedger(ctx *cli.Context, fn func(l *audit.Ledger) error) error {
+	s, err := openStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	return fn(audit.New(s.Audit()))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"faucet/internal/repo"
+	"fmt"
+	"path/filepath"
+
+	"github.com/urfave/cli"
+)
+
+var configCMD = cli.Command{
+	Name:  "config",
+	Usage: "Inspect the faucet config",
+	Subcommands: []cli.Command{
+		{
+			Name:   "check",
+			Usage:  "Validate faucet.toml and list every invalid field",
+			Action: configCheck,
+		},
+	},
+}
+
+func configCheck(ctx *cli.Context) error {
+	repoRoot, err := repo.PathRootWithDefault(ctx.GlobalString("repo"))
+	if err != nil {
+		return err
+	}
+	config, err := repo.UnmarshalConfig(repoRoot)
+	if err != nil {
+		return fmt.Errorf("init config error: %s", err)
+	}
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	fmt.Printf("%s is valid\n", filepath.Join(repoRoot, repo.ConfigName))
+	return nil
+}
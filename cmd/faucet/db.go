@@ -0,0 +1,154 @@
+package main
+
+import (
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli"
+)
+
+var dbCMD = cli.Command{
+	Name:  "db",
+	Usage: "Manage the faucet store",
+	Subcommands: []cli.Command{
+		{
+			Name:   "migrate",
+			Usage:  "Upgrade the store to the current schema version",
+			Action: dbMigrate,
+		},
+		{
+			Name:   "version",
+			Usage:  "Show the schema version of the store",
+			Action: dbVersion,
+		},
+		{
+			Name:  "export",
+			Usage: "Dump all faucet records as JSONL",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "output, o",
+					Usage: "Output file, default to stdout",
+				},
+			},
+			Action: dbExport,
+		},
+		{
+			Name:      "import",
+			Usage:     "Load a JSONL dump into an empty store",
+			ArgsUsage: "<file>",
+			Action:    dbImport,
+		},
+	},
+}
+
+func dbMigrate(ctx *cli.Context) error {
+	s, err := openRawStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	res, err := s.Migrate()
+	if err != nil {
+		return err
+	}
+	if res.From == res.To {
+		fmt.Printf("store is already at schema version %d\n", res.To)
+		return nil
+	}
+	fmt.Printf("migrated store from schema version %d to %d, %d records upgraded\n", res.From, res.To, res.Migrated)
+	return nil
+}
+
+func dbVersion(ctx *cli.Context) error {
+	s, err := openRawStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	version, err := s.Version()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("store schema version: %d, supported: %d\n", version, store.SchemaVersion())
+	return nil
+}
+
+func dbExport(ctx *cli.Context) error {
+	s, err := openRawStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	output := ctx.String("output")
+	if output == "" {
+		_, err := s.Export(os.Stdout)
+		return err
+	}
+	count, err := store.Backup(s, output)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("exported %d records to %s\n", count, output)
+	return nil
+}
+
+func dbImport(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return fmt.Errorf("usage: faucet db import <file>")
+	}
+	f, err := os.Open(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s, err := openRawStore(ctx)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	count, err := s.Import(f)
+	if err != nil {
+		return fmt.Errorf("import after %d records: %w", count, err)
+	}
+	fmt.Printf("imported %d records from %s\n", count, ctx.Args().First())
+	return nil
+}
+
+// openStore 直接打开 faucet 的 leveldb，leveldb 不支持多进程访问，faucet 运行时请使用 admin api
+func openStore(ctx *cli.Context) (store.Store, error) {
+	s, err := openRawStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.Version()
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	if version != store.SchemaVersion() {
+		s.Close()
+		return nil, fmt.Errorf("store schema version is %d, expect %d, run `faucet db migrate` first", version, store.SchemaVersion())
+	}
+	return s, nil
+}
+
+// openRawStore 打开 store 但不检查 schema 版本，供 db 子命令使用
+func openRawStore(ctx *cli.Context) (store.Store, error) {
+	repoRoot, err := repo.PathRootWithDefault(ctx.GlobalString("repo"))
+	if err != nil {
+		return nil, err
+	}
+	s, err := store.Open(filepath.Join(repoRoot, repo.StoreName))
+	if err != nil {
+		return nil, fmt.Errorf("open store (is faucet running? use the admin api instead): %w", err)
+	}
+	return s, nil
+}
@@ -29,6 +29,11 @@ func main() {
 	app.Commands = []cli.Command{
 		initCMD,
 		startCMD,
+		aclCMD,
+		apikeyCMD,
+		dbCMD,
+		auditCMD,
+		configCMD,
 	}
 
 	err := app.Run(os.Args)
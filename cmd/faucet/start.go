@@ -38,6 +38,10 @@ func start(ctx *cli.Context) error {
 	if err != nil {
 		return fmt.Errorf("init config error: %s", err)
 	}
+	// 启动前检查全部配置，避免在运行中才暴露错误
+	if err := config.Validate(); err != nil {
+		return err
+	}
 
 	err = log.Initialize(
 		log.WithReportCaller(config.Log.ReportCaller),
@@ -55,17 +59,20 @@ func start(ctx *cli.Context) error {
 	loggers.InitializeLogger(config)
 	repo.SetPath(repoRoot)
 
-	var server *app.Server
-	var wg sync.WaitGroup
-	wg.Add(1)
-	handleShutdown(server, &wg)
 	var client internal.Client
-	err = client.Initialize(repoRoot)
+	err = client.Initialize(config)
+	if err != nil {
+		return err
+	}
+	node, err := app.NewNode(&client)
 	if err != nil {
 		return err
 	}
-	server, _ = app.NewServer(&client)
-	if err := server.Start(); err != nil {
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	handleShutdown(node, &wg)
+	if err := node.Start(); err != nil {
 		return err
 	}
 	wg.Wait()
@@ -74,15 +81,16 @@ func start(ctx *cli.Context) error {
 	return nil
 }
 
-func handleShutdown(server *app.Server, wg *sync.WaitGroup) {
-	var stop = make(chan os.Signal)
+// handleShutdown 收到退出信号后停止 node，node 按启动的相反顺序停止各服务
+func handleShutdown(node internal.Lifecycle, wg *sync.WaitGroup) {
+	var stop = make(chan os.Signal, 1)
 	signal.Notify(stop, syscall.SIGTERM)
 	signal.Notify(stop, syscall.SIGINT)
 
 	go func() {
 		<-stop
 		fmt.Println("received interrupt signal, shutting down...")
-		if err := server.Stop(); err != nil {
+		if err := node.Stop(); err != nil {
 			logger.Error("faucet stop: ", err)
 		}
 
@@ -0,0 +1,206 @@
+package acl
+
+import (
+	"faucet/internal/store"
+	"faucet/internal/utils"
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	Allow = "allow"
+	Deny  = "deny"
+
+	KindAddress = "address"
+	KindIp      = "ip"
+	KindCidr    = "cidr"
+)
+
+// Decision 名单检查结果
+type Decision int
+
+const (
+	// None 不在任何名单中，按正常规则处理
+	None Decision = iota
+	// Allowed 命中白名单，免除冷却限制
+	Allowed
+	// Denied 命中黑名单，拒绝领取
+	Denied
+)
+
+type Entry = store.ACLEntry
+
+// ACL 持久化的地址、ip、网段黑白名单，启动时全部加载到内存
+type ACL struct {
+	repo store.ACLRepository
+
+	lock    sync.RWMutex
+	entries map[string]*Entry
+	// addresses/prefixes 按名单索引，ip 统一存为 /32 或 /128 网段
+	addresses map[string]map[string]bool
+	prefixes  map[string][]netip.Prefix
+}
+
+func New(repo store.ACLRepository) (*ACL, error) {
+	entries, err := repo.List()
+	if err != nil {
+		return nil, err
+	}
+	a := &ACL{
+		repo:    repo,
+		entries: make(map[string]*Entry),
+	}
+	for _, entry := range entries {
+		a.entries[entryKey(entry.List, entry.Value)] = entry
+	}
+	a.reindex()
+	return a, nil
+}
+
+// Add 加入名单，value 可以是地址、ip 或 CIDR，重复添加会覆盖备注
+func (a *ACL) Add(list string, value string, comment string) (*Entry, error) {
+	if err := checkList(list); err != nil {
+		return nil, err
+	}
+	kind, normalized, err := Normalize(value)
+	if err != nil {
+		return nil, err
+	}
+	entry := &Entry{
+		List:      list,
+		Kind:      kind,
+		Value:     normalized,
+		Comment:   comment,
+		CreatedAt: time.Now().Unix(),
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if err := a.repo.Put(entry); err != nil {
+		return nil, err
+	}
+	a.entries[entryKey(list, normalized)] = entry
+	a.reindex()
+	return entry, nil
+}
+
+// Remove 移出名单
+func (a *ACL) Remove(list string, value string) error {
+	if err := checkList(list); err != nil {
+		return err
+	}
+	_, normalized, err := Normalize(value)
+	if err != nil {
+		return err
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	key := entryKey(list, normalized)
+	if _, ok := a.entries[key]; !ok {
+		return fmt.Errorf("%s not in %s list", normalized, list)
+	}
+	if err := a.repo.Delete(list, normalized); err != nil {
+		return err
+	}
+	delete(a.entries, key)
+	a.reindex()
+	return nil
+}
+
+// Entries 返回名单条目，list 为空时返回全部
+func (a *ACL) Entries(list string) []*Entry {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	var entries []*Entry
+	for _, entry := range a.entries {
+		if list == "" || entry.List == list {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].List != entries[j].List {
+			return entries[i].List < entries[j].List
+		}
+		return entries[i].Value < entries[j].Value
+	})
+	return entries
+}
+
+// Check 检查领取地址和客户端 ip，黑名单优先于白名单
+func (a *ACL) Check(address string, ip string) Decision {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	if a.match(Deny, address, ip) {
+		return Denied
+	}
+	if a.match(Allow, address, ip) {
+		return Allowed
+	}
+	return None
+}
+
+func (a *ACL) match(list string, address string, ip string) bool {
+	if a.addresses[list][strings.ToLower(address)] {
+		return true
+	}
+	addr, ok := utils.ParseIp(ip)
+	if !ok {
+		return false
+	}
+	for _, prefix := range a.prefixes[list] {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *ACL) reindex() {
+	a.addresses = map[string]map[string]bool{Allow: {}, Deny: {}}
+	a.prefixes = make(map[string][]netip.Prefix)
+	for _, entry := range a.entries {
+		switch entry.Kind {
+		case KindAddress:
+			a.addresses[entry.List][entry.Value] = true
+		case KindIp:
+			addr, _ := utils.ParseIp(entry.Value)
+			a.prefixes[entry.List] = append(a.prefixes[entry.List], netip.PrefixFrom(addr, addr.BitLen()))
+		case KindCidr:
+			prefix, _ := netip.ParsePrefix(entry.Value)
+			a.prefixes[entry.List] = append(a.prefixes[entry.List], prefix)
+		}
+	}
+}
+
+// Normalize 识别条目类型并规范化：地址转小写，ip 去掉端口和 zone，CIDR 取网络地址
+func Normalize(value string) (string, string, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "0x") && common.IsHexAddress(value) {
+		return KindAddress, strings.ToLower(value), nil
+	}
+	if prefix, err := netip.ParsePrefix(value); err == nil {
+		return KindCidr, prefix.Masked().String(), nil
+	}
+	if addr, ok := utils.ParseIp(value); ok {
+		return KindIp, addr.String(), nil
+	}
+	return "", "", fmt.Errorf("invalid acl value: %s, expect address, ip or cidr", value)
+}
+
+func checkList(list string) error {
+	if list != Allow && list != Deny {
+		return fmt.Errorf("invalid acl list: %s, expect %s or %s", list, Allow, Deny)
+	}
+	return nil
+}
+
+func entryKey(list string, value string) string {
+	return list + "-" + value
+}
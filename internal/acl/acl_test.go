@@ -0,0 +1,66 @@
+package acl
+
+import (
+	"faucet/internal/store"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	s := store.New(store.NewMemory())
+	a, err := New(s.ACLs())
+	require.Nil(t, err)
+
+	_, err = a.Add(Deny, "0xAbCdEf0000000000000000000000000000000001", "bot")
+	require.Nil(t, err)
+	_, err = a.Add(Deny, "203.0.113.0/24", "")
+	require.Nil(t, err)
+	_, err = a.Add(Allow, "10.1.2.3", "ci runner")
+	require.Nil(t, err)
+	_, err = a.Add(Allow, "2001:db8::/32", "")
+	require.Nil(t, err)
+
+	require.Equal(t, Denied, a.Check("0xabcdef0000000000000000000000000000000001", "8.8.8.8"))
+	require.Equal(t, Denied, a.Check("0x0000000000000000000000000000000000000002", "203.0.113.77"))
+	require.Equal(t, Allowed, a.Check("0x0000000000000000000000000000000000000002", "10.1.2.3"))
+	require.Equal(t, Allowed, a.Check("0x0000000000000000000000000000000000000002", "2001:db8::1"))
+	require.Equal(t, None, a.Check("0x0000000000000000000000000000000000000002", "8.8.8.8"))
+	// 黑名单优先
+	require.Equal(t, Denied, a.Check("0xabcdef0000000000000000000000000000000001", "10.1.2.3"))
+
+	// 重新加载后名单仍然生效
+	reloaded, err := New(s.ACLs())
+	require.Nil(t, err)
+	require.Len(t, reloaded.Entries(""), 4)
+	require.Len(t, reloaded.Entries(Allow), 2)
+	require.Equal(t, Allowed, reloaded.Check("", "10.1.2.3"))
+
+	require.Nil(t, reloaded.Remove(Allow, "10.1.2.3"))
+	require.Equal(t, None, reloaded.Check("", "10.1.2.3"))
+	require.NotNil(t, reloaded.Remove(Allow, "10.1.2.3"))
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		value string
+		kind  string
+		want  string
+	}{
+		{"0xAbCdEf0000000000000000000000000000000001", KindAddress, "0xabcdef0000000000000000000000000000000001"},
+		{"203.0.113.9/24", KindCidr, "203.0.113.0/24"},
+		{" 10.0.0.1 ", KindIp, "10.0.0.1"},
+		{"::ffff:10.0.0.1", KindIp, "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		kind, value, err := Normalize(tt.value)
+		require.Nil(t, err)
+		require.Equal(t, tt.kind, kind)
+		require.Equal(t, tt.want, value)
+	}
+
+	_, _, err := Normalize("abcdef")
+	require.NotNil(t, err)
+	_, err = (&ACL{}).Add("grey", "10.0.0.1", "")
+	require.NotNil(t, err)
+}
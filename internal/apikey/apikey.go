@@ -0,0 +1,147 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"faucet/internal/store"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	secretPrefix = "fct_"
+	// idLength 列表和吊销时使用哈希前缀作为 key 的标识
+	idLength = 12
+)
+
+var (
+	ErrInvalidKey      = errors.New("invalid api key")
+	ErrRevokedKey      = errors.New("api key revoked")
+	ErrBudgetExhausted = errors.New("api key daily budget exhausted")
+)
+
+// Key 只保存 api key 的 sha256，明文只在签发时返回一次
+type Key = store.APIKey
+
+type Store struct {
+	repo store.APIKeyRepository
+	now  func() time.Time
+	lock sync.Mutex
+}
+
+func New(repo store.APIKeyRepository) *Store {
+	return &Store{repo: repo, now: time.Now}
+}
+
+// Issue 签发新的 api key，返回只展示一次的明文
+func (s *Store) Issue(name string, tier string) (string, *Key, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("generate api key: %w", err)
+	}
+	secret := secretPrefix + hex.EncodeToString(raw)
+	hash := Hash(secret)
+	key := &Key{
+		ID:        hash[:idLength],
+		Name:      name,
+		Tier:      tier,
+		Hash:      hash,
+		CreatedAt: s.now().Unix(),
+	}
+	if err := s.repo.Put(key); err != nil {
+		return "", nil, err
+	}
+	return secret, key, nil
+}
+
+// Lookup 校验请求携带的明文 api key
+func (s *Store) Lookup(secret string) (*Key, error) {
+	if !strings.HasPrefix(secret, secretPrefix) {
+		return nil, ErrInvalidKey
+	}
+	key, err := s.repo.Get(Hash(secret))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, ErrInvalidKey
+	}
+	if key.Revoked {
+		return nil, ErrRevokedKey
+	}
+	return key, nil
+}
+
+// List 按签发时间排序返回所有 key，包括已吊销的
+func (s *Store) List() ([]*Key, error) {
+	keys, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt < keys[j].CreatedAt
+	})
+	return keys, nil
+}
+
+// Revoke 吊销 key，id 为 List 展示的哈希前缀
+func (s *Store) Revoke(id string) error {
+	keys, err := s.List()
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if key.ID == id {
+			key.Revoked = true
+			return s.repo.Put(key)
+		}
+	}
+	return fmt.Errorf("api key %s not found", id)
+}
+
+// Reserve 占用 key 当天的额度，发放失败时需要调用 Release 归还
+func (s *Store) Reserve(key *Key, amount float64, dailyBudget float64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	spent, err := s.repo.Usage(key.Hash, now)
+	if err != nil {
+		return err
+	}
+	if dailyBudget > 0 && spent+amount > dailyBudget {
+		return ErrBudgetExhausted
+	}
+	return s.repo.SetUsage(key.Hash, now, spent+amount)
+}
+
+// Release 归还 Reserve 占用的额度
+func (s *Store) Release(key *Key, amount float64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	spent, err := s.repo.Usage(key.Hash, now)
+	if err != nil {
+		return err
+	}
+	spent -= amount
+	if spent < 0 {
+		spent = 0
+	}
+	return s.repo.SetUsage(key.Hash, now, spent)
+}
+
+// Spent key 当天已经领取的数量
+func (s *Store) Spent(key *Key) (float64, error) {
+	return s.repo.Usage(key.Hash, s.now())
+}
+
+// Hash api key 落盘前的哈希
+func Hash(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
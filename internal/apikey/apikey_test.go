@@ -0,0 +1,67 @@
+package apikey
+
+import (
+	"faucet/internal/store"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueAndLookup(t *testing.T) {
+	s := New(store.New(store.NewMemory()).APIKeys())
+	secret, key, err := s.Issue("ci", "ci")
+	require.Nil(t, err)
+	require.True(t, strings.HasPrefix(secret, secretPrefix))
+	require.Equal(t, Hash(secret), key.Hash)
+
+	found, err := s.Lookup(secret)
+	require.Nil(t, err)
+	require.Equal(t, key.ID, found.ID)
+	_, err = s.Lookup(secretPrefix + "unknown")
+	require.Equal(t, ErrInvalidKey, err)
+
+	require.Nil(t, s.Revoke(key.ID))
+	_, err = s.Lookup(secret)
+	require.Equal(t, ErrRevokedKey, err)
+	require.NotNil(t, s.Revoke("missing"))
+}
+
+func TestReserve(t *testing.T) {
+	s := New(store.New(store.NewMemory()).APIKeys())
+	_, key, err := s.Issue("ci", "ci")
+	require.Nil(t, err)
+
+	require.Nil(t, s.Reserve(key, 2, 3))
+	require.Equal(t, ErrBudgetExhausted, s.Reserve(key, 2, 3))
+	require.Nil(t, s.Release(key, 2))
+	require.Nil(t, s.Reserve(key, 3, 3))
+	spent, err := s.Spent(key)
+	require.Nil(t, err)
+	require.Equal(t, float64(3), spent)
+}
+
+func TestReserveRollover(t *testing.T) {
+	now := time.Date(2023, 8, 10, 23, 30, 0, 0, time.UTC)
+	s := New(store.New(store.NewMemory()).APIKeys())
+	s.now = func() time.Time { return now }
+	_, key, err := s.Issue("ci", "ci")
+	require.Nil(t, err)
+
+	require.Nil(t, s.Reserve(key, 3, 3))
+	require.Equal(t, ErrBudgetExhausted, s.Reserve(key, 1, 3))
+	// 额度按 UTC 自然日重置
+	now = now.Add(time.Hour)
+	require.Nil(t, s.Reserve(key, 3, 3))
+	// 0 表示不限额
+	require.Nil(t, s.Reserve(key, 100, 0))
+}
+
+func TestLookupRejectsForeignSecret(t *testing.T) {
+	s := New(store.New(store.NewMemory()).APIKeys())
+	secret, _, err := s.Issue("ci", "ci")
+	require.Nil(t, err)
+	_, err = s.Lookup(strings.TrimPrefix(secret, secretPrefix))
+	require.Equal(t, ErrInvalidKey, err)
+}
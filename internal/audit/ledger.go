@@ -0,0 +1,223 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"faucet/internal/store"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DecisionAllow = "allow"
+	DecisionDeny  = "deny"
+
+	// StatusRejected 请求在发送交易前被拒绝
+	StatusRejected = "rejected"
+	// StatusFailed 交易发送失败或上链执行失败
+	StatusFailed = "failed"
+	// StatusUnconfirmed 交易已广播，但在等待时间内没有拿到回执
+	StatusUnconfirmed = "unconfirmed"
+	StatusConfirmed   = "confirmed"
+)
+
+var ErrTampered = errors.New("audit ledger tampered")
+
+type Entry = store.AuditEntry
+
+// Ledger 只追加的审计账本，每条记录包含上一条记录的哈希，修改或删除中间的记录都会被 Verify 发现
+type Ledger struct {
+	repo store.AuditRepository
+	now  func() time.Time
+	lock sync.Mutex
+
+	subLock     sync.Mutex
+	subscribers map[chan *Entry]struct{}
+}
+
+func New(repo store.AuditRepository) *Ledger {
+	return &Ledger{repo: repo, now: time.Now, subscribers: make(map[chan *Entry]struct{})}
+}
+
+// Subscribe 订阅之后写入账本的记录，返回的函数用于取消订阅。
+// 推送不会阻塞写入，订阅方处理不过来、缓冲区已满时丢弃新记录
+func (l *Ledger) Subscribe(buffer int) (<-chan *Entry, func()) {
+	ch := make(chan *Entry, buffer)
+	l.subLock.Lock()
+	l.subscribers[ch] = struct{}{}
+	l.subLock.Unlock()
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			l.subLock.Lock()
+			delete(l.subscribers, ch)
+			l.subLock.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (l *Ledger) publish(entry *Entry) {
+	l.subLock.Lock()
+	defer l.subLock.Unlock()
+	for ch := range l.subscribers {
+		copied := *entry
+		select {
+		case ch <- &copied:
+		default:
+		}
+	}
+}
+
+// Record 补全序号、时间和哈希后写入账本
+func (l *Ledger) Record(entry *Entry) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	last, err := l.repo.Last()
+	if err != nil {
+		return err
+	}
+	entry.Seq = 1
+	entry.PrevHash = ""
+	if last != nil {
+		entry.Seq = last.Seq + 1
+		entry.PrevHash = last.Hash
+	}
+	entry.Time = l.now().UnixNano()
+	entry.Hash = Hash(entry)
+	if err := l.repo.Append(entry); err != nil {
+		return err
+	}
+	l.publish(entry)
+	return nil
+}
+
+// Range 返回 [from, to) 内的记录
+func (l *Ledger) Range(from time.Time, to time.Time) ([]*Entry, error) {
+	var entries []*Entry
+	err := l.repo.Range(from, to, func(entry *Entry) bool {
+		entries = append(entries, entry)
+		return true
+	})
+	return entries, err
+}
+
+// FindTx 返回交易哈希对应的最后一条记录，没有记录过时返回 nil
+func (l *Ledger) FindTx(txHash string) (*Entry, error) {
+	return l.repo.FindTx(txHash)
+}
+
+// Verify 从第一条开始校验哈希链，返回校验通过的条数
+func (l *Ledger) Verify() (int, error) {
+	var (
+		count     int
+		prevHash  string
+		verifyErr error
+	)
+	err := l.repo.Scan(func(entry *Entry) bool {
+		if entry.Seq != uint64(count+1) {
+			verifyErr = fmt.Errorf("%w: expect seq %d, got %d", ErrTampered, count+1, entry.Seq)
+			return false
+		}
+		if entry.PrevHash != prevHash {
+			verifyErr = fmt.Errorf("%w: entry %d does not link to the previous entry", ErrTampered, entry.Seq)
+			return false
+		}
+		if Hash(entry) != entry.Hash {
+			verifyErr = fmt.Errorf("%w: entry %d hash mismatch", ErrTampered, entry.Seq)
+			return false
+		}
+		prevHash = entry.Hash
+		count++
+		return true
+	})
+	if err != nil {
+		return count, err
+	}
+	return count, verifyErr
+}
+
+// Hash 除 Hash 字段外所有字段的 sha256
+func Hash(entry *Entry) string {
+	e := *entry
+	e.Hash = ""
+	data, _ := json.Marshal(&e)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+var csvHeader = []string{
+	"seq", "time", "source", "net", "address", "ip", "user_agent", "api_key",
+	"decision", "reason", "tx_hash", "amount", "gas_used", "status", "prev_hash", "hash",
+}
+
+// WriteCSV 导出为 CSV，时间为 RFC3339 格式的 UTC 时间
+func WriteCSV(w io.Writer, entries []*Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		err := cw.Write([]string{
+			strconv.FormatUint(e.Seq, 10),
+			time.Unix(0, e.Time).UTC().Format(time.RFC3339Nano),
+			e.Source, csvText(e.Net), csvText(e.Address), csvText(e.IP), csvText(e.UserAgent), e.APIKey,
+			e.Decision, csvText(e.Reason), e.TxHash,
+			strconv.FormatFloat(e.Amount, 'f', -1, 64),
+			strconv.FormatUint(e.GasUsed, 10),
+			e.Status, e.PrevHash, e.Hash,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// csvText 中和请求方可控的字段：以 = + - @ 等开头的单元格会被表格软件当作公式执行，前面补一个单引号
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// ParseTimeRange 解析查询的时间范围，支持 RFC3339 和 2006-01-02 两种格式，from 默认最早，to 默认当前时间
+func ParseTimeRange(from string, to string) (time.Time, time.Time, error) {
+	start := time.Unix(0, 0)
+	end := time.Now()
+	var err error
+	if from != "" {
+		if start, err = parseTime(from); err != nil {
+			return start, end, err
+		}
+	}
+	if to != "" {
+		if end, err = parseTime(to); err != nil {
+			return start, end, err
+		}
+	}
+	if !end.After(start) {
+		return start, end, fmt.Errorf("invalid time range: %s - %s", from, to)
+	}
+	return start, end, nil
+}
+
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return t, fmt.Errorf("invalid time %s, expect RFC3339 or 2006-01-02", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"faucet/internal/store"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndVerify(t *testing.T) {
+	backend := store.NewMemory()
+	s := store.New(backend)
+	l := New(s.Audit())
+	now := time.Date(2023, 8, 1, 12, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	for i, status := range []string{StatusRejected, StatusConfirmed, StatusFailed} {
+		now = now.Add(time.Hour)
+		require.Nil(t, l.Record(&Entry{Net: "axm", Address: "0xabc", Status: status}))
+		last, err := s.Audit().Last()
+		require.Nil(t, err)
+		require.Equal(t, uint64(i+1), last.Seq)
+	}
+	count, err := l.Verify()
+	require.Nil(t, err)
+	require.Equal(t, 3, count)
+
+	entries, err := l.Range(time.Date(2023, 8, 1, 13, 30, 0, 0, time.UTC), time.Date(2023, 8, 1, 15, 0, 0, 0, time.UTC))
+	require.Nil(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, StatusConfirmed, entries[0].Status)
+	require.Equal(t, Hash(entries[0]), entries[0].Hash)
+
+	// 修改中间的记录会被发现
+	entries[0].Status = StatusFailed
+	require.Nil(t, s.Audit().Append(entries[0]))
+	count, err = l.Verify()
+	require.True(t, errors.Is(err, ErrTampered))
+	require.Equal(t, 1, count)
+}
+
+func TestFindTx(t *testing.T) {
+	l := New(store.New(store.NewMemory()).Audit())
+	require.Nil(t, l.Record(&Entry{Net: "axm", Address: "0xabc", TxHash: "0xAB", Status: StatusUnconfirmed}))
+	require.Nil(t, l.Record(&Entry{Net: "axm", Address: "0xabc", Status: StatusRejected}))
+	require.Nil(t, l.Record(&Entry{Net: "axm", Address: "0xabc", TxHash: "0xab", Status: StatusConfirmed}))
+
+	entry, err := l.FindTx("0xAb")
+	require.Nil(t, err)
+	require.Equal(t, uint64(3), entry.Seq)
+	require.Equal(t, StatusConfirmed, entry.Status)
+	entry, err = l.FindTx("0xcd")
+	require.Nil(t, err)
+	require.Nil(t, entry)
+}
+
+func TestSubscribe(t *testing.T) {
+	l := New(store.New(store.NewMemory()).Audit())
+	events, cancel := l.Subscribe(1)
+	require.Nil(t, l.Record(&Entry{Net: "axm", Address: "0xabc", Status: StatusConfirmed}))
+	// 缓冲区已满，不阻塞写入
+	require.Nil(t, l.Record(&Entry{Net: "axm", Address: "0xdef", Status: StatusRejected}))
+
+	entry := <-events
+	require.Equal(t, uint64(1), entry.Seq)
+	require.Equal(t, "0xabc", entry.Address)
+	cancel()
+	_, ok := <-events
+	require.False(t, ok)
+	cancel()
+	require.Nil(t, l.Record(&Entry{Net: "axm", Address: "0xabc", Status: StatusConfirmed}))
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []*Entry{{Seq: 1, Time: time.Unix(1690000000, 0).UnixNano(), Net: "axm", Reason: "blocked, by acl", Amount: 0.5}})
+	require.Nil(t, err)
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.Nil(t, err)
+	require.Len(t, records, 2)
+	require.Equal(t, csvHeader, records[0])
+	require.Equal(t, "2023-07-22T04:26:40Z", records[1][1])
+	require.Equal(t, "blocked, by acl", records[1][9])
+	require.Equal(t, "0.5", records[1][11])
+}
+
+func TestWriteCSVNeutralisesFormulas(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteCSV(&buf, []*Entry{{UserAgent: "=HYPERLINK(\"http://evil\")", Reason: "@SUM(A1)", Address: "0xabc"}})
+	require.Nil(t, err)
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.Nil(t, err)
+	require.Equal(t, "'=HYPERLINK(\"http://evil\")", records[1][6])
+	require.Equal(t, "'@SUM(A1)", records[1][9])
+	require.Equal(t, "0xabc", records[1][4])
+}
+
+func TestParseTimeRange(t *testing.T) {
+	from, to, err := ParseTimeRange("2023-08-01", "2023-08-02T00:00:00Z")
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2023, 8, 1, 0, 0, 0, 0, time.Local), from)
+	require.True(t, to.Equal(time.Date(2023, 8, 2, 0, 0, 0, 0, time.UTC)))
+
+	_, _, err = ParseTimeRange("yesterday", "")
+	require.NotNil(t, err)
+	_, _, err = ParseTimeRange("2023-08-02", "2023-08-01")
+	require.NotNil(t, err)
+}
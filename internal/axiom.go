@@ -1,18 +1,23 @@
 package internal
 
 import (
-	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
-	"encoding/json"
+	"faucet/internal/acl"
+	"faucet/internal/apikey"
+	"faucet/internal/audit"
+	"faucet/internal/budget"
+	"faucet/internal/cooldown"
+	"faucet/internal/errcode"
 	"faucet/internal/loggers"
 	"faucet/internal/repo"
-	"faucet/internal/utils"
-	"faucet/persist"
+	"faucet/internal/reserve"
+	"faucet/internal/store"
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -20,134 +25,316 @@ import (
 	"github.com/Rican7/retry"
 	"github.com/Rican7/retry/backoff"
 	"github.com/Rican7/retry/strategy"
-	"github.com/axiomesh/axiom-kit/storage"
-	"github.com/axiomesh/axiom-kit/storage/leveldb"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
 
 const (
 	nativeToken = "native"
-	amount      = 0.5
 )
 
 type Client struct {
-	Config          *repo.Config
+	Config *repo.Config
+	// ctx 在排空超时后取消，用于中止对交易确认的等待
 	ctx             context.Context
+	cancel          context.CancelFunc
+	drain           drain
 	axiomClient     *ethclient.Client
 	axiomLock       sync.Mutex
 	axiomAuth       *bind.TransactOpts
 	axiomPrivateKey *ecdsa.PrivateKey
-	ldb             storage.Storage
+	store           store.Store
+	ACL             *acl.ACL
+	APIKeys         *apikey.Store
+	Audit           *audit.Ledger
+	budgets         map[string]*budget.Budget
+	cooldowns       *cooldown.Limiter
+	scaler          *reserve.Scaler
+	control         *control
+	claimLocks      keyLock
 	logger          logrus.FieldLogger
-	GinContext      *gin.Context
+	// sendTx 和 receipt 发送领取交易和查询回执，测试中可以替换
+	sendTx  func(toAddr string, amount float64) (string, error)
+	receipt func(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
 }
 
-type AddressData struct {
-	SendTxTime int64   `json:"sendTxTime"`
-	TxHash     string  `json:"txHash"`
-	Amount     float64 `json:"amount"`
+// DripRequest 一次领取请求
+type DripRequest struct {
+	Net     string
+	Address string
+	// Source 请求来源，写入审计账本
+	Source string
+	// IP 经可信代理解析出的客户端 ip，为空时不做 ip 每日次数限制
+	IP        string
+	UserAgent string
+	// SkipCooldown 白名单中的地址或 ip 不受冷却限制
+	SkipCooldown bool
+	// APIKey 和 Tier 为携带 api key 的请求，按 tier 的数量、冷却配置和每日额度发放
+	APIKey *apikey.Key
+	Tier   *repo.Tier
 }
 
-func (c *Client) SendTra(net string, address string) (string, error) {
-	var (
-		txHash string
-		err    error
-	)
-	lowerAddress := strings.ToLower(address)
-	// 合法校验：每天每个(net + type + addr)只发一个
-	if err := c.checkLimit(net, nativeToken, lowerAddress, c.ldb); err != nil {
+// SendTra 检查限制后发放，无论成功与否都会写入一条审计记录
+func (c *Client) SendTra(req *DripRequest) (txHash string, err error) {
+	entry := &audit.Entry{
+		Source:    req.Source,
+		Net:       req.Net,
+		Address:   strings.ToLower(req.Address),
+		IP:        req.IP,
+		UserAgent: req.UserAgent,
+		Decision:  audit.DecisionDeny,
+		Status:    audit.StatusRejected,
+	}
+	if req.APIKey != nil {
+		entry.APIKey = req.APIKey.ID
+	}
+	defer func() {
+		if err != nil {
+			entry.Reason = err.Error()
+		}
+		c.RecordAudit(entry)
+	}()
+
+	if !c.drain.begin() {
+		return "", errcode.New(errcode.ShuttingDown, "faucet is shutting down")
+	}
+	defer c.drain.done()
+
+	if c.IsPaused(req.Net) {
+		return "", errcode.New(errcode.FaucetPaused, "faucet is paused for net: %s", req.Net)
+	}
+	lowerAddress := strings.ToLower(req.Address)
+	amount := c.Amount(req.Net)
+	if req.Tier != nil && req.Tier.Amount > 0 {
+		amount = req.Tier.Amount
+	}
+	policy := c.Config.Axiom.CooldownFor(nativeToken, req.Tier)
+	ip := req.IP
+	if req.SkipCooldown {
+		ip = ""
+	}
+	// 同一地址和 ip 的领取串行执行，检查、发送和记录之间不会插入其他领取
+	unlock := c.lockClaim(req.Net, lowerAddress, ip)
+	defer unlock()
+	// 合法校验：每个(net + type + addr)在冷却窗口内最多领取 max_claims 次，已广播未确认的领取同样计入
+	if !req.SkipCooldown {
+		sentAt, ipCount, err := c.pendingClaims(req.Net, lowerAddress, ip)
+		if err != nil {
+			return "", err
+		}
+		if err := c.cooldowns.Check(req.Net, nativeToken, lowerAddress, policy, sentAt...); err != nil {
+			return "", err
+		}
+		if err := c.checkIpQuota(req.Net, ip, ipCount); err != nil {
+			return "", err
+		}
+	}
+	if c.scaler != nil {
+		amount, err = c.scaleAmount(req.Net, amount)
+		if err != nil {
+			return "", err
+		}
+	}
+	reservation, err := c.Budget(req.Net).Reserve(amount)
+	if err != nil {
 		return "", err
 	}
-	txHash, err = sendTxAxm(c, address, amount)
+	if req.APIKey != nil && req.Tier != nil {
+		if err := c.APIKeys.Reserve(req.APIKey, amount, req.Tier.DailyBudget); err != nil {
+			if err := c.Budget(req.Net).Release(reservation); err != nil {
+				c.logger.Errorf("release budget: %s", err)
+			}
+			return "", err
+		}
+	}
+	entry.Decision = audit.DecisionAllow
+	entry.Amount = amount
+	entry.Status = audit.StatusFailed
+	// release 没有转出资金时归还占用的额度
+	release := func() {
+		if err := c.Budget(req.Net).Release(reservation); err != nil {
+			c.logger.Errorf("release budget: %s", err)
+		}
+		if req.APIKey != nil && req.Tier != nil {
+			if err := c.APIKeys.Release(req.APIKey, amount); err != nil {
+				c.logger.Errorf("release api key budget: %s", err)
+			}
+		}
+	}
+	txHash, err = c.sendTx(req.Address, amount)
 	if err != nil {
+		release()
 		return "", err
 	}
-	if checkTxSuccess(c, txHash) {
-		if err := putTxData(txHash, c, lowerAddress, nativeToken, net); err != nil {
+	entry.TxHash = txHash
+	entry.Status = audit.StatusUnconfirmed
+	pending := &PendingTx{Net: req.Net, Address: lowerAddress, TxHash: txHash, Amount: amount}
+	if req.Tier != nil {
+		pending.Tier = req.Tier.Name
+	}
+	pending.IP = ip
+	c.addPending(pending)
+	receipt, ok := checkTxSuccess(c, txHash)
+	if receipt != nil {
+		entry.GasUsed = receipt.GasUsed
+	}
+	switch {
+	case ok:
+		entry.Status = audit.StatusConfirmed
+		if err := c.recordClaim(pending); err != nil {
+			// 交易留在待确认列表中，由 Tracker 重新补记
 			return "", fmt.Errorf("putTxDataFailed: %w", err)
 		}
+		c.removePending(txHash)
+	case receipt != nil:
+		// 交易执行失败，资金没有转出
+		entry.Status = audit.StatusFailed
+		release()
+		c.removePending(txHash)
+	case c.ctx.Err() != nil:
+		c.logger.Warnf("stop waiting for tx %s on shutdown, keep it pending", txHash)
+	default:
+		// 没有等到回执的交易留在待确认列表中，由 Tracker 确认后补记冷却和 ip 配额
+		c.logger.Infof("tx %s not confirmed yet, keep it pending", txHash)
 	}
 	return txHash, nil
 }
 
-func putTxData(txHash string, c *Client, address string, typ string, net string) error {
-	p := &AddressData{
-		SendTxTime: time.Now().Unix(),
-		TxHash:     txHash,
-		Amount:     amount,
+// recordClaim 按广播时间记录确认上链的领取：地址冷却，以及不在白名单中时的 ip 配额
+func (c *Client) recordClaim(tx *PendingTx) error {
+	// tier 已从配置中删除时使用默认冷却
+	tier, _ := c.Config.Axiom.Tier(tx.Tier)
+	policy := c.Config.Axiom.CooldownFor(nativeToken, tier)
+	sentAt := time.Unix(tx.SentAt, 0)
+	if err := c.cooldowns.RecordAt(tx.Net, nativeToken, tx.Address, policy, tx.TxHash, tx.Amount, sentAt); err != nil {
+		return err
 	}
-	structJSON, err := json.Marshal(p)
-	if err != nil {
-		return fmt.Errorf("json marshal failed: %w", err)
+	if tx.IP != "" {
+		if _, err := c.store.IPQuotas().Incr(tx.Net, tx.IP, sentAt); err != nil {
+			c.logger.Errorf("incr ip quota of %s: %s", tx.IP, err)
+		}
 	}
-	c.ldb.Put(c.construAddressKey(net, typ, address), structJSON)
 	return nil
 }
 
-func deleteTxData(c *Client, address string, typ string, net string) error {
-	c.ldb.Delete(c.construAddressKey(net, typ, address))
-	return nil
+// lockClaim 取得地址的锁，ip 不为空时再取得 ip 的锁，总是先地址后 ip，不会死锁
+func (c *Client) lockClaim(net string, address string, ip string) func() {
+	unlockAddress := c.claimLocks.Lock("address|" + net + "|" + address)
+	if ip == "" {
+		return unlockAddress
+	}
+	unlockIP := c.claimLocks.Lock("ip|" + net + "|" + ip)
+	return func() {
+		unlockIP()
+		unlockAddress()
+	}
 }
 
-func (c *Client) construAddressKey(net string, typ string, address string) []byte {
-	// get public_ip and ip with address and net tobe key
-	var buffer bytes.Buffer
-	buffer.WriteString(address)
-	buffer.WriteString("-")
-	buffer.WriteString(typ)
-	c.logger.Infof("construKey: %s ", buffer)
-	return persist.CompositeKey(net, buffer)
+// pendingClaims 已广播、尚未记录的领取：地址的广播时间，以及 ip 在当前 UTC 自然日的次数
+func (c *Client) pendingClaims(net string, address string, ip string) ([]int64, int, error) {
+	txs, err := c.store.Jobs().List()
+	if err != nil {
+		return nil, 0, err
+	}
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	var sentAt []int64
+	ipCount := 0
+	for _, tx := range txs {
+		if tx.Net != net {
+			continue
+		}
+		if tx.Address == address {
+			sentAt = append(sentAt, tx.SentAt)
+		}
+		if ip != "" && tx.IP == ip && !time.Unix(tx.SentAt, 0).Before(today) {
+			ipCount++
+		}
+	}
+	return sentAt, ipCount, nil
 }
 
-func (c *Client) construIpKey(net string) []byte {
-	// get public_ip and ip with address and net tobe key
-	var buffer bytes.Buffer
-	buffer.WriteString(time.Now().Format("2006-01-02"))
-	buffer.WriteString("-")
-	buffer.WriteString(utils.ClientPublicIP(c.GinContext.Request))
-	buffer.WriteString("-")
-	buffer.WriteString(utils.ClientIp(c.GinContext.Request))
-	c.logger.Infof("construKey: %s ", buffer)
-	return persist.CompositeKey(net, buffer)
+// checkIpQuota 同一个 ip 每天最多领取 ip_daily_limit 次，0 表示不限，pending 为尚未记录的次数
+func (c *Client) checkIpQuota(net string, ip string, pending int) error {
+	limit := c.Config.Axiom.IPDailyLimit
+	if limit <= 0 || ip == "" {
+		return nil
+	}
+	count, err := c.store.IPQuotas().Count(net, ip, time.Now())
+	if err != nil {
+		return err
+	}
+	if count+pending >= limit {
+		// 计数按 UTC 自然日记录
+		return errcode.New(errcode.IPQuotaExceeded, "The ip has reached the daily claim limit").WithRetry(errcode.UntilNextUTCDay(time.Now()))
+	}
+	return nil
 }
 
-func (c *Client) checkLimit(net string, typ string, address string, ldb storage.Storage) error {
-	value := ldb.Get(c.construAddressKey(net, typ, address))
-	if value != nil {
-		data := AddressData{}
-		if err := json.Unmarshal(value, &data); err != nil {
-			return fmt.Errorf("unmarshal error")
-		}
-		// 获取当前时间的 Unix 时间戳
-		currentUnixTime := time.Now().Unix()
+// scaleAmount 按水龙头余额和最近的发放速率缩放领取数量
+func (c *Client) scaleAmount(net string, base float64) (float64, error) {
+	balance, err := faucetBalance(c)
+	if err != nil {
+		return 0, err
+	}
+	burned, err := c.Budget(net).Spent(c.scaler.BurnWindow())
+	if err != nil {
+		return 0, err
+	}
+	scaled, err := c.scaler.Scale(base, balance, burned)
+	if err != nil {
+		c.logger.Warnf("faucet balance %v, burned %v in %s: %s", balance, burned, c.scaler.BurnWindow(), err)
+		return 0, err
+	}
+	if scaled != base {
+		c.logger.Infof("drip amount scaled from %v to %v, faucet balance %v", base, scaled, balance)
+	}
+	return scaled, nil
+}
 
-		// 计算时间差（以秒为单位）
-		timeDifference := currentUnixTime - data.SendTxTime
+// Store 水龙头的持久化数据
+func (c *Client) Store() store.Store {
+	return c.store
+}
+
+// Backup 在线备份 store 到 RepoRoot/backups 下，返回备份文件路径和记录条数
+func (c *Client) Backup() (string, int, error) {
+	path := filepath.Join(c.Config.RepoRoot, repo.BackupName, fmt.Sprintf("store-%s.jsonl", time.Now().Format("20060102-150405")))
+	count, err := store.Backup(c.store, path)
+	if err != nil {
+		return "", 0, err
+	}
+	c.logger.Infof("store backed up to %s, %d records", path, count)
+	return path, count, nil
+}
 
-		// 定义一天的秒数
-		oneDayInSeconds := int64(24 * 60 * 60)
+// Budget 返回 net 的全局发放额度
+func (c *Client) Budget(net string) *budget.Budget {
+	return c.budgets[strings.ToLower(net)]
+}
 
-		// 比较时间差与一天的秒数
-		if timeDifference <= oneDayInSeconds {
-			return fmt.Errorf("The address has recently received test tokens")
-		}
+// Nets 按名称排序的已启用 net
+func (c *Client) Nets() []string {
+	nets := make([]string, 0, len(c.budgets))
+	for net := range c.budgets {
+		nets = append(nets, net)
 	}
-	return nil
+	sort.Strings(nets)
+	return nets
 }
 
-func checkTxSuccess(c *Client, txHash string) bool {
-	client := c.axiomClient
+// checkTxSuccess 等待交易回执，返回最后一次拿到的回执以及交易是否执行成功
+func checkTxSuccess(c *Client, txHash string) (*types.Receipt, bool) {
+	var receipt *types.Receipt
 	err := retry.Retry(func(attempt uint) error {
-		receipt, err := client.TransactionReceipt(context.Background(), common.HexToHash(txHash))
+		r, err := c.receipt(c.ctx, common.HexToHash(txHash))
 		if err != nil {
 			return err
 		}
+		receipt = r
 		if err == nil && receipt != nil {
 			if receipt.Status == types.ReceiptStatusFailed {
 				return fmt.Errorf("faucet transfer failed")
@@ -156,18 +343,22 @@ func checkTxSuccess(c *Client, txHash string) bool {
 		return nil
 	}, strategy.Limit(3), strategy.Backoff(backoff.Fibonacci(200*time.Millisecond)))
 	if err != nil {
-		return false
+		return receipt, false
 	}
-	return true
+	return receipt, true
 
 }
 
-func (c *Client) Initialize(configPath string) error {
-	c.ctx = context.Background()
-	cfg, err := repo.UnmarshalConfig(configPath)
-	if err != nil {
-		return fmt.Errorf("unmarshal config for plugin :%w", err)
+// RecordAudit 写入审计账本，写入失败只记录日志，不影响领取结果
+func (c *Client) RecordAudit(entry *audit.Entry) {
+	if err := c.Audit.Record(entry); err != nil {
+		c.logger.Errorf("record audit entry of %s: %s", entry.Address, err)
 	}
+}
+
+// Initialize 使用已经通过 Validate 检查的配置建立链上节点连接和 store
+func (c *Client) Initialize(cfg *repo.Config) error {
+	c.ctx, c.cancel = context.WithCancel(context.Background())
 	c.Config = cfg
 	// 构建axiom客户端
 	axiomClient, err := ethclient.Dial(cfg.Axiom.AxiomAddr)
@@ -175,9 +366,13 @@ func (c *Client) Initialize(configPath string) error {
 		return fmt.Errorf("dial axiom node: %w", err)
 	}
 	c.axiomClient = axiomClient
+	c.receipt = axiomClient.TransactionReceipt
+	c.sendTx = func(toAddr string, amount float64) (string, error) {
+		return sendTxAxm(c, toAddr, amount)
+	}
 
 	// 构建auth_axm
-	keyPathAxm := filepath.Join(configPath, cfg.Axiom.AxiomKeyPath)
+	keyPathAxm := filepath.Join(cfg.RepoRoot, cfg.Axiom.AxiomKeyPath)
 	keyByteAxm, err := ioutil.ReadFile(keyPathAxm)
 	if err != nil {
 		return err
@@ -185,26 +380,64 @@ func (c *Client) Initialize(configPath string) error {
 	private := strings.TrimSpace(string(keyByteAxm))
 	privateKeyBytes, err := hex.DecodeString(private)
 	if err != nil {
-		return fmt.Errorf("Error decoding private key hex:", err)
+		return fmt.Errorf("Error decoding private key hex: %w", err)
 	}
 	privateKey, err := crypto.ToECDSA(privateKeyBytes)
 	if err != nil {
-		return fmt.Errorf("Error converting to ECDSA private key:", err)
+		return fmt.Errorf("Error converting to ECDSA private key: %w", err)
 	}
 	c.axiomPrivateKey = privateKey
 	authAxm := bind.NewKeyedTransactor(privateKey)
 	c.axiomAuth = authAxm
 
 	// 初始化leveldb
-	leveldb, err := leveldb.New(filepath.Join(c.Config.RepoRoot, "store"))
+	s, err := store.Open(filepath.Join(c.Config.RepoRoot, repo.StoreName))
+	if err != nil {
+		return fmt.Errorf("open store: %w", err)
+	}
+	c.store = s
+	res, err := s.Migrate()
 	if err != nil {
-		return fmt.Errorf("create tm-leveldb: %w", err)
+		return err
+	}
+	if res.From != res.To {
+		loggers.Logger(loggers.ApiServer).Infof("store migrated from schema version %d to %d, %d records upgraded", res.From, res.To, res.Migrated)
+	}
+	c.control = newControl()
+	c.ACL, err = acl.New(s.ACLs())
+	if err != nil {
+		return fmt.Errorf("load acl: %w", err)
+	}
+	c.APIKeys = apikey.New(s.APIKeys())
+	c.Audit = audit.New(s.Audit())
+	c.cooldowns = cooldown.New(s.Claims(), time.Now)
+	c.budgets = map[string]*budget.Budget{
+		"axm": budget.New(s.Budgets(), "axm", cfg.Axiom.Budget),
+	}
+	if cfg.Axiom.DynamicAmount.Enable {
+		c.scaler, err = reserve.NewScaler(cfg.Axiom.DynamicAmount)
+		if err != nil {
+			return fmt.Errorf("init dynamic amount: %w", err)
+		}
 	}
-	c.ldb = leveldb
 	c.logger = loggers.Logger(loggers.ApiServer)
 	return nil
 }
+
+// Start 连接和 store 在 Initialize 中建立，作为服务注册时只需要负责关闭
+func (c *Client) Start() error {
+	return nil
+}
+
+// Stop 在依赖 client 的服务都停止之后关闭 store 和链上节点连接
+func (c *Client) Stop() error {
+	c.Close()
+	return nil
+}
+
+// Close 关闭 store 和链上节点连接，应在 Drain 之后调用
 func (c *Client) Close() {
-	c.ldb.Close()
+	c.cancel()
+	c.store.Close()
 	c.axiomClient.Close()
 }
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"faucet/internal/audit"
+	"faucet/internal/budget"
+	"faucet/internal/cooldown"
+	"faucet/internal/loggers"
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newDripClient 不连接链上节点的 client，发送的交易依次编号
+func newDripClient(now func() time.Time) (*Client, store.Store) {
+	s := store.New(store.NewMemory())
+	var sent int64
+	c := &Client{
+		Config: &repo.Config{Axiom: repo.AXIOM{
+			Amount:       0.5,
+			Cooldown:     repo.Cooldown{Period: 24 * time.Hour, MaxClaims: 1, Mode: "sliding"},
+			IPDailyLimit: 1,
+		}},
+		ctx:       context.Background(),
+		store:     s,
+		Audit:     audit.New(s.Audit()),
+		budgets:   map[string]*budget.Budget{"axm": budget.New(s.Budgets(), "axm", repo.Budget{})},
+		cooldowns: cooldown.New(s.Claims(), now),
+		control:   newControl(),
+		logger:    loggers.Logger(loggers.ApiServer),
+	}
+	c.sendTx = func(toAddr string, amount float64) (string, error) {
+		return common.BigToHash(big.NewInt(atomic.AddInt64(&sent, 1))).Hex(), nil
+	}
+	c.receipt = func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+		return nil, ethereum.NotFound
+	}
+	return c, s
+}
+
+func TestSendTraKeepsUnconfirmedTx(t *testing.T) {
+	now := time.Now()
+	c, s := newDripClient(func() time.Time { return now })
+	req := &DripRequest{Net: "axm", Address: "0xABC", IP: "10.0.0.1"}
+
+	txHash, err := c.SendTra(req)
+	require.Nil(t, err)
+	pending, err := c.PendingTxs()
+	require.Nil(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, txHash, pending[0].TxHash)
+	require.Equal(t, "10.0.0.1", pending[0].IP)
+	sentAt := pending[0].SentAt
+	status, err := c.ClaimStatus(txHash)
+	require.Nil(t, err)
+	require.Equal(t, audit.StatusUnconfirmed, status.Status)
+
+	// 尚未确认的领取同样计入冷却
+	_, err = c.SendTra(&DripRequest{Net: "axm", Address: "0xabc", IP: "10.0.0.2"})
+	var cooldownErr *cooldown.Error
+	require.True(t, errors.As(err, &cooldownErr))
+
+	tracker := NewTracker(c)
+	tracker.now = func() time.Time { return now.Add(trackerMinAge) }
+	tracker.receipt = func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+		return &types.Receipt{Status: types.ReceiptStatusSuccessful}, nil
+	}
+	require.Nil(t, tracker.Check(context.Background()))
+	pending, err = c.PendingTxs()
+	require.Nil(t, err)
+	require.Empty(t, pending)
+
+	claim, err := s.Claims().Get("axm", nativeToken, "0xabc")
+	require.Nil(t, err)
+	require.Equal(t, []int64{sentAt}, claim.History)
+	_, err = c.SendTra(req)
+	require.True(t, errors.As(err, &cooldownErr))
+	count, err := s.IPQuotas().Count("axm", "10.0.0.1", now)
+	require.Nil(t, err)
+	require.Equal(t, 1, count)
+}
+
+func TestSendTraConcurrent(t *testing.T) {
+	c, _ := newDripClient(time.Now)
+	c.receipt = func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+		return &types.Receipt{Status: types.ReceiptStatusSuccessful}, nil
+	}
+	send := c.sendTx
+	// 拉长检查和记录之间的间隔，没有串行化时并发的请求都能通过检查
+	c.sendTx = func(toAddr string, amount float64) (string, error) {
+		time.Sleep(10 * time.Millisecond)
+		return send(toAddr, amount)
+	}
+	drip := func(reqs []*DripRequest) int {
+		var wg sync.WaitGroup
+		var lock sync.Mutex
+		sent := 0
+		for _, req := range reqs {
+			wg.Add(1)
+			go func(req *DripRequest) {
+				defer wg.Done()
+				if _, err := c.SendTra(req); err == nil {
+					lock.Lock()
+					sent++
+					lock.Unlock()
+				}
+			}(req)
+		}
+		wg.Wait()
+		return sent
+	}
+
+	// 同一地址从不同 ip 并发领取，max_claims 为 1
+	var reqs []*DripRequest
+	for i := 0; i < 10; i++ {
+		reqs = append(reqs, &DripRequest{Net: "axm", Address: "0xabc", IP: fmt.Sprintf("10.0.0.%d", i)})
+	}
+	require.Equal(t, 1, drip(reqs))
+
+	// 同一 ip 为不同地址并发领取，ip_daily_limit 为 1
+	reqs = nil
+	for i := 0; i < 10; i++ {
+		reqs = append(reqs, &DripRequest{Net: "axm", Address: fmt.Sprintf("0xdef%d", i), IP: "10.0.1.1"})
+	}
+	require.Equal(t, 1, drip(reqs))
+}
+
+func TestSendTraReleasesBudgetOnFailedReceipt(t *testing.T) {
+	c, _ := newDripClient(time.Now)
+	c.receipt = func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+		return &types.Receipt{Status: types.ReceiptStatusFailed}, nil
+	}
+	_, err := c.SendTra(&DripRequest{Net: "axm", Address: "0xabc"})
+	require.Nil(t, err)
+	spent, err := c.Budget("axm").Spent(time.Hour)
+	require.Nil(t, err)
+	require.Zero(t, spent)
+	pending, err := c.PendingTxs()
+	require.Nil(t, err)
+	require.Empty(t, pending)
+
+	c.receipt = func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+		return &types.Receipt{Status: types.ReceiptStatusSuccessful}, nil
+	}
+	_, err = c.SendTra(&DripRequest{Net: "axm", Address: "0xabc"})
+	require.Nil(t, err)
+	spent, err = c.Budget("axm").Spent(time.Hour)
+	require.Nil(t, err)
+	require.Equal(t, 0.5, spent)
+}
@@ -0,0 +1,151 @@
+package budget
+
+import (
+	"errors"
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// bucketSize 发放量按分钟分桶累计，滚动窗口按桶求和
+	bucketSize = time.Minute
+	hour       = time.Hour
+	day        = 24 * time.Hour
+)
+
+var ErrExhausted = errors.New("faucet budget exhausted")
+
+// Status 当前窗口内的额度，Hourly/Daily 为 0 表示不限
+type Status struct {
+	Hourly          float64 `json:"hourly"`
+	HourlyRemaining float64 `json:"hourlyRemaining"`
+	Daily           float64 `json:"daily"`
+	DailyRemaining  float64 `json:"dailyRemaining"`
+}
+
+// Reservation 一次占用的额度，发放失败时通过 Release 归还
+type Reservation struct {
+	bucket int64
+	amount float64
+}
+
+// Budget 单个 net 的全局发放额度，按最近一小时和最近 24 小时滚动计算
+type Budget struct {
+	repo   store.BudgetRepository
+	net    string
+	hourly float64
+	daily  float64
+	now    func() time.Time
+	lock   sync.Mutex
+}
+
+func New(budgets store.BudgetRepository, net string, cfg repo.Budget) *Budget {
+	return &Budget{
+		repo:   budgets,
+		net:    net,
+		hourly: cfg.Hourly,
+		daily:  cfg.Daily,
+		now:    time.Now,
+	}
+}
+
+// Reserve 检查两个窗口的剩余额度并占用 amount
+func (b *Budget) Reserve(amount float64) (*Reservation, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now()
+	if err := b.repo.Prune(b.net, bucketOf(now.Add(-day))); err != nil {
+		return nil, err
+	}
+	if b.hourly > 0 {
+		spent, err := b.spent(now, hour)
+		if err != nil {
+			return nil, err
+		}
+		if spent+amount > b.hourly {
+			return nil, fmt.Errorf("%w: hourly limit %v reached, try again later", ErrExhausted, b.hourly)
+		}
+	}
+	if b.daily > 0 {
+		spent, err := b.spent(now, day)
+		if err != nil {
+			return nil, err
+		}
+		if spent+amount > b.daily {
+			return nil, fmt.Errorf("%w: daily limit %v reached, try again later", ErrExhausted, b.daily)
+		}
+	}
+
+	bucket := bucketOf(now)
+	if err := b.add(bucket, amount); err != nil {
+		return nil, err
+	}
+	return &Reservation{bucket: bucket, amount: amount}, nil
+}
+
+// Release 归还占用的额度
+func (b *Budget) Release(r *Reservation) error {
+	if r == nil {
+		return nil
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.add(r.bucket, -r.amount)
+}
+
+// Spent 最近 window 内的发放量，window 最长 24 小时
+func (b *Budget) Spent(window time.Duration) (float64, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.spent(b.now(), window)
+}
+
+func (b *Budget) Status() (*Status, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := b.now()
+	hourly, err := b.spent(now, hour)
+	if err != nil {
+		return nil, err
+	}
+	daily, err := b.spent(now, day)
+	if err != nil {
+		return nil, err
+	}
+	return &Status{
+		Hourly:          b.hourly,
+		HourlyRemaining: remaining(b.hourly, hourly),
+		Daily:           b.daily,
+		DailyRemaining:  remaining(b.daily, daily),
+	}, nil
+}
+
+func (b *Budget) spent(now time.Time, window time.Duration) (float64, error) {
+	return b.repo.Sum(b.net, bucketOf(now.Add(-window).Add(bucketSize)), bucketOf(now.Add(bucketSize)))
+}
+
+func (b *Budget) add(bucket int64, amount float64) error {
+	total, err := b.repo.Get(b.net, bucket)
+	if err != nil {
+		return err
+	}
+	return b.repo.Set(b.net, bucket, total+amount)
+}
+
+func bucketOf(t time.Time) int64 {
+	return t.Unix() / int64(bucketSize.Seconds())
+}
+
+func remaining(limit float64, spent float64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	if spent >= limit {
+		return 0
+	}
+	return limit - spent
+}
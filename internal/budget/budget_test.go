@@ -0,0 +1,68 @@
+package budget
+
+import (
+	"errors"
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReserve(t *testing.T) {
+	now := time.Unix(1690000000, 0)
+	b := New(store.New(store.NewMemory()).Budgets(), "axm", repo.Budget{Hourly: 2, Daily: 3})
+	b.now = func() time.Time { return now }
+
+	r1, err := b.Reserve(1)
+	require.Nil(t, err)
+	_, err = b.Reserve(1)
+	require.Nil(t, err)
+	_, err = b.Reserve(1)
+	require.True(t, errors.Is(err, ErrExhausted))
+
+	// 发放失败归还后可以再次占用
+	require.Nil(t, b.Release(r1))
+	_, err = b.Reserve(1)
+	require.Nil(t, err)
+
+	// 一小时后小时额度恢复，日额度仍然受限
+	now = now.Add(time.Hour)
+	_, err = b.Reserve(1)
+	require.Nil(t, err)
+	_, err = b.Reserve(1)
+	require.True(t, errors.Is(err, ErrExhausted))
+
+	status, err := b.Status()
+	require.Nil(t, err)
+	require.Equal(t, float64(1), status.HourlyRemaining)
+	require.Equal(t, float64(0), status.DailyRemaining)
+
+	now = now.Add(24 * time.Hour)
+	spent, err := b.Spent(24 * time.Hour)
+	require.Nil(t, err)
+	require.Equal(t, float64(0), spent)
+}
+
+func TestBucketRollover(t *testing.T) {
+	now := time.Unix(1690000000, 0)
+	b := New(store.New(store.NewMemory()).Budgets(), "axm", repo.Budget{Hourly: 1})
+	b.now = func() time.Time { return now }
+
+	r, err := b.Reserve(1)
+	require.Nil(t, err)
+	// 窗口按分钟桶滚动，满一小时前仍然占用
+	now = now.Add(59 * time.Minute)
+	_, err = b.Reserve(1)
+	require.True(t, errors.Is(err, ErrExhausted))
+	now = now.Add(time.Minute)
+	_, err = b.Reserve(1)
+	require.Nil(t, err)
+
+	// 归还落在原来的桶，不影响当前窗口
+	require.Nil(t, b.Release(r))
+	_, err = b.Reserve(1)
+	require.True(t, errors.Is(err, ErrExhausted))
+	require.Nil(t, b.Release(nil))
+}
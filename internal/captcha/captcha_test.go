@@ -0,0 +1,58 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"faucet/internal/repo"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func stubSiteVerify(t *testing.T, secret string, body map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Nil(t, r.ParseForm())
+		require.Equal(t, secret, r.PostForm.Get("secret"))
+		require.Equal(t, "1.2.3.4", r.PostForm.Get("remoteip"))
+		if r.PostForm.Get("response") != "good-token" {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"success":     false,
+				"error-codes": []string{"invalid-input-response"},
+			})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+}
+
+func TestVerify(t *testing.T) {
+	for _, provider := range []string{HCaptcha, ReCaptcha, Turnstile} {
+		srv := stubSiteVerify(t, "secret", map[string]interface{}{"success": true})
+		verifier, err := New(repo.Captcha{Provider: provider, Secret: "secret", Endpoint: srv.URL})
+		require.Nil(t, err)
+
+		require.Nil(t, verifier.Verify(context.Background(), "good-token", "1.2.3.4"))
+		require.ErrorIs(t, verifier.Verify(context.Background(), "bad-token", "1.2.3.4"), ErrVerificationFailed)
+		require.ErrorIs(t, verifier.Verify(context.Background(), "", "1.2.3.4"), ErrMissingToken)
+		srv.Close()
+	}
+}
+
+func TestReCaptchaScore(t *testing.T) {
+	srv := stubSiteVerify(t, "secret", map[string]interface{}{"success": true, "score": 0.3})
+	defer srv.Close()
+
+	verifier, err := New(repo.Captcha{Provider: ReCaptcha, Secret: "secret", Endpoint: srv.URL, MinScore: 0.5})
+	require.Nil(t, err)
+	require.ErrorIs(t, verifier.Verify(context.Background(), "good-token", "1.2.3.4"), ErrVerificationFailed)
+}
+
+func TestNewInvalid(t *testing.T) {
+	_, err := New(repo.Captcha{Provider: "unknown", Secret: "secret"})
+	require.NotNil(t, err)
+
+	_, err = New(repo.Captcha{Provider: HCaptcha})
+	require.NotNil(t, err)
+}
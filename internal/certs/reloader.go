@@ -0,0 +1,144 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+)
+
+// Reloader 持有 tls 证书，证书或私钥文件变化后自动重新加载。
+// 监听的是文件所在目录，以便覆盖改名替换和 k8s secret 的符号链接切换；
+// 证书和私钥不是同时写入时，中间的加载失败只记录日志，继续使用旧证书
+type Reloader struct {
+	certFile string
+	keyFile  string
+	logger   logrus.FieldLogger
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewReloader 加载证书并开始监听文件变化，用完需要 Close
+func NewReloader(certFile, keyFile string, logger logrus.FieldLogger) (*Reloader, error) {
+	r := &Reloader{
+		certFile: filepath.Clean(certFile),
+		keyFile:  filepath.Clean(keyFile),
+		logger:   logger,
+		done:     make(chan struct{}),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("watch tls cert: %w", err)
+	}
+	for _, dir := range []string{filepath.Dir(r.certFile), filepath.Dir(r.keyFile)} {
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return nil, fmt.Errorf("watch %s: %w", dir, err)
+		}
+	}
+	r.watcher = watcher
+	r.wg.Add(1)
+	go r.watch()
+	return r, nil
+}
+
+// Reload 重新读取证书和私钥，失败时保留当前证书
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load tls cert %s: %w", r.certFile, err)
+	}
+	r.mu.Lock()
+	r.cert = &cert
+	r.mu.Unlock()
+	return nil
+}
+
+// GetCertificate 用作 tls.Config.GetCertificate，每次握手取当前证书
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// TLSConfig 返回使用当前证书的服务端配置
+func (r *Reloader) TLSConfig() *tls.Config {
+	return &tls.Config{
+		GetCertificate: r.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+}
+
+func (r *Reloader) Close() error {
+	close(r.done)
+	err := r.watcher.Close()
+	r.wg.Wait()
+	return err
+}
+
+func (r *Reloader) watch() {
+	defer r.wg.Done()
+	for {
+		select {
+		case <-r.done:
+			return
+		case event, ok := <-r.watcher.Events:
+			if !ok {
+				return
+			}
+			if !r.relevant(event) {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				r.logger.Warnf("reload tls cert after %s, keep the previous one: %s", event, err)
+				continue
+			}
+			r.logger.Infof("reloaded tls cert %s", r.certFile)
+		case err, ok := <-r.watcher.Errors:
+			if !ok {
+				return
+			}
+			r.logger.Errorf("watch tls cert: %s", err)
+		}
+	}
+}
+
+// relevant 只关心证书、私钥本身以及 k8s 挂载目录中 ..data 这类符号链接的变化
+func (r *Reloader) relevant(event fsnotify.Event) bool {
+	if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+		return false
+	}
+	name := filepath.Clean(event.Name)
+	if name == r.certFile || name == r.keyFile {
+		return true
+	}
+	base := filepath.Base(name)
+	return len(base) > 2 && base[:2] == ".."
+}
+
+// LoadCertPool 读取 PEM 格式的 CA 证书，用于校验客户端证书
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	caPEM, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("read client ca: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, fmt.Errorf("no certificate found in %s", file)
+	}
+	return pool, nil
+}
@@ -0,0 +1,88 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCert 生成序列号为 serial 的自签名证书，先写临时文件再改名，模拟证书轮换
+func writeCert(t *testing.T, dir string, serial int64) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.Nil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.Nil(t, err)
+
+	for name, block := range map[string]*pem.Block{
+		"tls.key": {Type: "EC PRIVATE KEY", Bytes: keyDER},
+		"tls.crt": {Type: "CERTIFICATE", Bytes: der},
+	} {
+		tmp := filepath.Join(dir, name+".tmp")
+		require.Nil(t, ioutil.WriteFile(tmp, pem.EncodeToMemory(block), 0600))
+		require.Nil(t, os.Rename(tmp, filepath.Join(dir, name)))
+	}
+}
+
+func serialOf(t *testing.T, r *Reloader) int64 {
+	cert, err := r.GetCertificate(nil)
+	require.Nil(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.Nil(t, err)
+	return leaf.SerialNumber.Int64()
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	writeCert(t, dir, 1)
+
+	r, err := NewReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), logrus.New())
+	require.Nil(t, err)
+	defer r.Close()
+	require.EqualValues(t, 1, serialOf(t, r))
+
+	writeCert(t, dir, 2)
+	require.Eventually(t, func() bool { return serialOf(t, r) == 2 }, 5*time.Second, 20*time.Millisecond)
+
+	// 损坏的证书不会替换当前证书
+	require.Nil(t, ioutil.WriteFile(filepath.Join(dir, "tls.crt"), []byte("broken"), 0600))
+	require.NotNil(t, r.Reload())
+	require.EqualValues(t, 2, serialOf(t, r))
+}
+
+func TestNewReloaderMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	_, err := NewReloader(filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), logrus.New())
+	require.NotNil(t, err)
+}
+
+func TestLoadCertPool(t *testing.T) {
+	dir := t.TempDir()
+	writeCert(t, dir, 1)
+	pool, err := LoadCertPool(filepath.Join(dir, "tls.crt"))
+	require.Nil(t, err)
+	require.NotNil(t, pool)
+
+	_, err = LoadCertPool(filepath.Join(dir, "tls.key"))
+	require.NotNil(t, err)
+}
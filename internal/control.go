@@ -0,0 +1,180 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"faucet/internal/audit"
+	"faucet/internal/errcode"
+	"faucet/internal/store"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// PendingTx 已广播、尚未确认上链的领取交易，持久化在 store 中
+type PendingTx = store.Job
+
+// balanceTTL /faucet/info 展示的余额缓存时间，避免每个请求都查询链上节点
+const balanceTTL = 5 * time.Second
+
+// control 运行时可以通过 admin api 调整的状态：暂停和领取数量，以及缓存的余额
+type control struct {
+	lock    sync.RWMutex
+	paused  map[string]bool
+	amounts map[string]float64
+
+	balanceLock sync.Mutex
+	balance     float64
+	balanceAt   time.Time
+	now         func() time.Time
+}
+
+func newControl() *control {
+	return &control{
+		paused:  make(map[string]bool),
+		amounts: make(map[string]float64),
+		now:     time.Now,
+	}
+}
+
+// cachedBalance 缓存未过期时直接返回，否则调用 load 查询；查询失败不更新缓存
+func (ctl *control) cachedBalance(load func() (float64, error)) (float64, error) {
+	ctl.balanceLock.Lock()
+	defer ctl.balanceLock.Unlock()
+	now := ctl.now()
+	if !ctl.balanceAt.IsZero() && now.Sub(ctl.balanceAt) < balanceTTL {
+		return ctl.balance, nil
+	}
+	balance, err := load()
+	if err != nil {
+		return 0, err
+	}
+	ctl.balance, ctl.balanceAt = balance, now
+	return balance, nil
+}
+
+// Pause 暂停 net 的发放，已经广播的交易不受影响
+func (c *Client) Pause(net string) {
+	c.control.lock.Lock()
+	defer c.control.lock.Unlock()
+	c.control.paused[strings.ToLower(net)] = true
+	c.logger.Warnf("dispensing paused for net: %s", net)
+}
+
+// Resume 恢复 net 的发放
+func (c *Client) Resume(net string) {
+	c.control.lock.Lock()
+	defer c.control.lock.Unlock()
+	delete(c.control.paused, strings.ToLower(net))
+	c.logger.Infof("dispensing resumed for net: %s", net)
+}
+
+func (c *Client) IsPaused(net string) bool {
+	c.control.lock.RLock()
+	defer c.control.lock.RUnlock()
+	return c.control.paused[strings.ToLower(net)]
+}
+
+// Amount 每次领取的数量，未通过 admin api 调整时使用配置值
+func (c *Client) Amount(net string) float64 {
+	c.control.lock.RLock()
+	defer c.control.lock.RUnlock()
+	if amount, ok := c.control.amounts[strings.ToLower(net)]; ok {
+		return amount
+	}
+	return c.Config.Axiom.Amount
+}
+
+// SetAmount 调整领取数量，只在内存中生效，重启后恢复配置值
+func (c *Client) SetAmount(net string, amount float64) error {
+	if amount <= 0 {
+		return errcode.New(errcode.InvalidAmount, "amount must be positive: %v", amount)
+	}
+	c.control.lock.Lock()
+	defer c.control.lock.Unlock()
+	c.control.amounts[strings.ToLower(net)] = amount
+	c.logger.Infof("drip amount for net %s set to %v", net, amount)
+	return nil
+}
+
+// PendingTxs 按广播时间排序的待确认交易
+func (c *Client) PendingTxs() ([]*PendingTx, error) {
+	txs, err := c.store.Jobs().List()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].SentAt < txs[j].SentAt
+	})
+	return txs, nil
+}
+
+// ClaimStatus 领取交易的上链状态，Status 取值与审计账本一致
+type ClaimStatus struct {
+	TxHash      string `json:"txHash"`
+	Status      string `json:"status"`
+	BlockNumber uint64 `json:"blockNumber,omitempty"`
+}
+
+// ClaimStatus 查询领取交易的状态：仍在待确认列表中的为 unconfirmed，其余以链上回执为准。
+// 只查询审计账本中记录过的交易，不是水龙头发出的交易返回 NOT_FOUND
+func (c *Client) ClaimStatus(txHash string) (*ClaimStatus, error) {
+	txs, err := c.store.Jobs().List()
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range txs {
+		if strings.EqualFold(tx.TxHash, txHash) {
+			return &ClaimStatus{TxHash: tx.TxHash, Status: audit.StatusUnconfirmed}, nil
+		}
+	}
+	entry, err := c.Audit.FindTx(txHash)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, errcode.New(errcode.NotFound, "claim not found: %s", txHash)
+	}
+	receipt, err := c.axiomClient.TransactionReceipt(context.Background(), common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, errcode.New(errcode.NotFound, "claim not found: %s", txHash)
+	}
+	if err != nil {
+		return nil, errcode.Wrap(errcode.ChainUnavailable, err)
+	}
+	status := &ClaimStatus{TxHash: receipt.TxHash.Hex(), Status: audit.StatusConfirmed, BlockNumber: receipt.BlockNumber.Uint64()}
+	if receipt.Status == types.ReceiptStatusFailed {
+		status.Status = audit.StatusFailed
+	}
+	return status, nil
+}
+
+// Balance 水龙头账户在 net 上的余额，单位 ether，结果缓存 balanceTTL
+func (c *Client) Balance(net string) (float64, error) {
+	return c.control.cachedBalance(func() (float64, error) {
+		return faucetBalance(c)
+	})
+}
+
+func (c *Client) addPending(tx *PendingTx) {
+	tx.SentAt = time.Now().Unix()
+	if err := c.store.Jobs().Put(tx); err != nil {
+		c.logger.Errorf("save pending tx %s: %s", tx.TxHash, err)
+	}
+}
+
+func (c *Client) removePending(txHash string) {
+	if err := c.store.Jobs().Delete(txHash); err != nil {
+		c.logger.Errorf("remove pending tx %s: %s", txHash, err)
+	}
+}
+
+// ResetCooldown 删除地址的领取记录，使其可以立即再次领取
+func (c *Client) ResetCooldown(net string, address string) error {
+	return c.cooldowns.Reset(net, nativeToken, strings.ToLower(address))
+}
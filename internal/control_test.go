@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"errors"
+	"faucet/internal/audit"
+	"faucet/internal/errcode"
+	"faucet/internal/store"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedBalance(t *testing.T) {
+	now := time.Unix(1690000000, 0)
+	ctl := newControl()
+	ctl.now = func() time.Time { return now }
+	calls := 0
+	load := func() (float64, error) {
+		calls++
+		return float64(calls), nil
+	}
+
+	balance, err := ctl.cachedBalance(load)
+	require.Nil(t, err)
+	require.Equal(t, float64(1), balance)
+	now = now.Add(balanceTTL - time.Second)
+	balance, err = ctl.cachedBalance(load)
+	require.Nil(t, err)
+	require.Equal(t, float64(1), balance)
+
+	now = now.Add(time.Second)
+	balance, err = ctl.cachedBalance(load)
+	require.Nil(t, err)
+	require.Equal(t, float64(2), balance)
+
+	// 缓存过期后查询失败直接返回错误
+	now = now.Add(balanceTTL)
+	_, err = ctl.cachedBalance(func() (float64, error) { return 0, errors.New("node down") })
+	require.NotNil(t, err)
+}
+
+func TestClaimStatusUnknownTx(t *testing.T) {
+	s := store.New(store.NewMemory())
+	c := &Client{store: s, Audit: audit.New(s.Audit())}
+	require.Nil(t, s.Jobs().Put(&PendingTx{Net: "axm", TxHash: "0xAB"}))
+
+	status, err := c.ClaimStatus("0xab")
+	require.Nil(t, err)
+	require.Equal(t, audit.StatusUnconfirmed, status.Status)
+
+	// 不是水龙头发出的交易不查询链上
+	_, err = c.ClaimStatus("0xcd")
+	var e *errcode.Error
+	require.True(t, errors.As(err, &e))
+	require.Equal(t, errcode.NotFound, e.Code)
+}
@@ -0,0 +1,135 @@
+package cooldown
+
+import (
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"sort"
+	"time"
+)
+
+const (
+	// ModeSliding 最近 Period 内最多 MaxClaims 次
+	ModeSliding = "sliding"
+	// ModeCalendar 每个 UTC 自然日最多 MaxClaims 次
+	ModeCalendar = "calendar"
+)
+
+// Error 地址在冷却中，RetryAt 之后可以再次领取
+type Error struct {
+	RetryAt time.Time
+}
+
+func (e *Error) Error() string {
+	return "The address has recently received test tokens"
+}
+
+// Retention 领取记录至少需要保留的时长
+func Retention(c repo.Cooldown) time.Duration {
+	if c.Mode == ModeCalendar {
+		return 24 * time.Hour
+	}
+	return c.Period
+}
+
+// Limiter 基于持久化的领取记录检查冷却，now 可以替换为测试用的时钟
+type Limiter struct {
+	claims store.ClaimRepository
+	now    func() time.Time
+}
+
+func New(claims store.ClaimRepository, now func() time.Time) *Limiter {
+	if now == nil {
+		now = time.Now
+	}
+	return &Limiter{claims: claims, now: now}
+}
+
+// Check 地址在当前窗口内的领取次数达到 MaxClaims 时返回 *Error，
+// pending 为已经广播、尚未确认记录的领取时间，同样计入次数
+func (l *Limiter) Check(net string, typ string, address string, c repo.Cooldown, pending ...int64) error {
+	claim, err := l.claims.Get(net, typ, address)
+	if err != nil {
+		return err
+	}
+	var times []int64
+	if claim != nil {
+		times = history(claim)
+	}
+	times = append(times, pending...)
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	now := l.now()
+	inWindow := within(times, windowStart(c, now))
+	if len(inWindow) < c.MaxClaims {
+		return nil
+	}
+	return &Error{RetryAt: retryAt(c, inWindow, now)}
+}
+
+// Record 记录一次刚确认上链的领取
+func (l *Limiter) Record(net string, typ string, address string, c repo.Cooldown, txHash string, amount float64) error {
+	return l.RecordAt(net, typ, address, c, txHash, amount, l.now())
+}
+
+// RecordAt 按交易广播的时间 at 记录一次确认上链的领取，只保留判断冷却所需的最近 MaxClaims 次
+func (l *Limiter) RecordAt(net string, typ string, address string, c repo.Cooldown, txHash string, amount float64, at time.Time) error {
+	claim, err := l.claims.Get(net, typ, address)
+	if err != nil {
+		return err
+	}
+	var times []int64
+	if claim != nil {
+		times = within(history(claim), windowStart(c, l.now()))
+	}
+	times = append(times, at.Unix())
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	if len(times) > c.MaxClaims {
+		times = times[len(times)-c.MaxClaims:]
+	}
+	return l.claims.Put(net, typ, address, &store.Claim{
+		SendTxTime: times[len(times)-1],
+		TxHash:     txHash,
+		Amount:     amount,
+		History:    times,
+	})
+}
+
+// Reset 删除地址的领取记录
+func (l *Limiter) Reset(net string, typ string, address string) error {
+	return l.claims.Delete(net, typ, address)
+}
+
+func history(claim *store.Claim) []int64 {
+	if len(claim.History) == 0 {
+		return []int64{claim.SendTxTime}
+	}
+	times := append([]int64(nil), claim.History...)
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	return times
+}
+
+// windowStart 窗口起点，与原来的实现一致，恰好 Period 之前的领取仍在窗口内
+func windowStart(c repo.Cooldown, now time.Time) time.Time {
+	if c.Mode == ModeCalendar {
+		return now.UTC().Truncate(24 * time.Hour)
+	}
+	return now.Add(-c.Period)
+}
+
+func within(times []int64, start time.Time) []int64 {
+	var res []int64
+	for _, t := range times {
+		if t >= start.Unix() {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// retryAt 窗口内最早的几次领取移出窗口、次数低于 MaxClaims 的时间
+func retryAt(c repo.Cooldown, inWindow []int64, now time.Time) time.Time {
+	if c.Mode == ModeCalendar {
+		return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	}
+	oldest := inWindow[len(inWindow)-c.MaxClaims]
+	return time.Unix(oldest, 0).Add(c.Period + time.Second)
+}
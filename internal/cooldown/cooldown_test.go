@@ -0,0 +1,112 @@
+package cooldown
+
+import (
+	"errors"
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) now() time.Time { return f.t }
+
+func newLimiter(start time.Time) (*Limiter, *fakeClock, store.ClaimRepository) {
+	clock := &fakeClock{t: start}
+	claims := store.New(store.NewMemory()).Claims()
+	return New(claims, clock.now), clock, claims
+}
+
+func TestSliding(t *testing.T) {
+	l, clock, _ := newLimiter(time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC))
+	policy := repo.Cooldown{Period: time.Hour, MaxClaims: 2, Mode: ModeSliding}
+
+	require.Nil(t, l.Check("axm", "native", "0xabc", policy))
+	require.Nil(t, l.Record("axm", "native", "0xabc", policy, "0x1", 1))
+	clock.t = clock.t.Add(10 * time.Minute)
+	require.Nil(t, l.Check("axm", "native", "0xabc", policy))
+	require.Nil(t, l.Record("axm", "native", "0xabc", policy, "0x2", 1))
+
+	err := l.Check("axm", "native", "0xabc", policy)
+	var cooldownErr *Error
+	require.True(t, errors.As(err, &cooldownErr))
+	require.Equal(t, time.Date(2023, 8, 1, 11, 0, 1, 0, time.UTC), cooldownErr.RetryAt.UTC())
+
+	// 恰好一个 period 时仍在窗口内
+	clock.t = time.Date(2023, 8, 1, 11, 0, 0, 0, time.UTC)
+	require.NotNil(t, l.Check("axm", "native", "0xabc", policy))
+	clock.t = clock.t.Add(time.Second)
+	require.Nil(t, l.Check("axm", "native", "0xabc", policy))
+
+	// 其他地址和 net 不受影响
+	require.Nil(t, l.Check("axm", "native", "0xdef", policy))
+	require.Nil(t, l.Check("eth", "native", "0xabc", policy))
+}
+
+func TestCheckPending(t *testing.T) {
+	now := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	l, _, _ := newLimiter(now)
+	policy := repo.Cooldown{Period: time.Hour, MaxClaims: 2, Mode: ModeSliding}
+
+	require.Nil(t, l.Record("axm", "native", "0xabc", policy, "0x1", 1))
+	require.Nil(t, l.Check("axm", "native", "0xabc", policy))
+	// 已广播未记录的领取计入次数，窗口外的不计入
+	require.NotNil(t, l.Check("axm", "native", "0xabc", policy, now.Add(-time.Minute).Unix()))
+	require.Nil(t, l.Check("axm", "native", "0xabc", policy, now.Add(-2*time.Hour).Unix()))
+	require.NotNil(t, l.Check("axm", "native", "0xdef", policy, now.Unix(), now.Unix()))
+}
+
+func TestCalendar(t *testing.T) {
+	l, clock, _ := newLimiter(time.Date(2023, 8, 1, 23, 50, 0, 0, time.UTC))
+	policy := repo.Cooldown{MaxClaims: 1, Mode: ModeCalendar}
+
+	require.Nil(t, l.Record("axm", "native", "0xabc", policy, "0x1", 1))
+	err := l.Check("axm", "native", "0xabc", policy)
+	var cooldownErr *Error
+	require.True(t, errors.As(err, &cooldownErr))
+	require.Equal(t, time.Date(2023, 8, 2, 0, 0, 0, 0, time.UTC), cooldownErr.RetryAt)
+
+	// 跨过 UTC 零点即可再次领取
+	clock.t = time.Date(2023, 8, 2, 0, 0, 0, 0, time.UTC)
+	require.Nil(t, l.Check("axm", "native", "0xabc", policy))
+}
+
+func TestRecordTrimsHistory(t *testing.T) {
+	l, clock, claims := newLimiter(time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC))
+	policy := repo.Cooldown{Period: time.Hour, MaxClaims: 3, Mode: ModeSliding}
+	for i := 0; i < 5; i++ {
+		require.Nil(t, l.Record("axm", "native", "0xabc", policy, "0x1", 1))
+		clock.t = clock.t.Add(time.Minute)
+	}
+	claim, err := claims.Get("axm", "native", "0xabc")
+	require.Nil(t, err)
+	require.Len(t, claim.History, 3)
+
+	require.Nil(t, l.Reset("axm", "native", "0xabc"))
+	require.Nil(t, l.Check("axm", "native", "0xabc", policy))
+}
+
+func TestLegacyClaim(t *testing.T) {
+	now := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	l, _, claims := newLimiter(now)
+	// 升级前的记录只有 SendTxTime
+	require.Nil(t, claims.Put("axm", "native", "0xabc", &store.Claim{SendTxTime: now.Add(-time.Hour).Unix()}))
+	require.NotNil(t, l.Check("axm", "native", "0xabc", repo.Cooldown{Period: 24 * time.Hour, MaxClaims: 1, Mode: ModeSliding}))
+	require.Nil(t, l.Check("axm", "native", "0xabc", repo.Cooldown{Period: 24 * time.Hour, MaxClaims: 2, Mode: ModeSliding}))
+}
+
+func TestCooldownFor(t *testing.T) {
+	a := &repo.AXIOM{
+		Cooldown:       repo.Cooldown{Period: 24 * time.Hour, MaxClaims: 1, Mode: ModeSliding},
+		TokenCooldowns: map[string]repo.Cooldown{"native": {MaxClaims: 2}},
+		Tiers:          []repo.Tier{{Name: "ci", Cooldown: repo.Cooldown{Mode: ModeCalendar}}},
+	}
+	require.Equal(t, repo.Cooldown{Period: 24 * time.Hour, MaxClaims: 2, Mode: ModeSliding}, a.CooldownFor("native", nil))
+	require.Equal(t, repo.Cooldown{Period: 24 * time.Hour, MaxClaims: 2, Mode: ModeCalendar}, a.CooldownFor("native", &a.Tiers[0]))
+	require.Equal(t, repo.Cooldown{Period: 24 * time.Hour, MaxClaims: 1, Mode: ModeSliding}, a.CooldownFor("erc20", nil))
+}
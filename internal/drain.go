@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"context"
+	"sync"
+)
+
+// drain 跟踪进行中的发放，退出时先拒绝新的发放，再等待进行中的发放结束
+type drain struct {
+	lock     sync.Mutex
+	draining bool
+	inflight sync.WaitGroup
+}
+
+// begin 登记一次发放，排空开始后返回 false；Add 和 Wait 在同一把锁下切换，不会并发
+func (d *drain) begin() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.draining {
+		return false
+	}
+	d.inflight.Add(1)
+	return true
+}
+
+func (d *drain) done() {
+	d.inflight.Done()
+}
+
+// Drain 拒绝新的发放并等待进行中的发放结束。ctx 到期后不再等待交易确认，
+// 已广播但未确认的交易保留在待确认列表中，重启后仍可查询
+func (c *Client) Drain(ctx context.Context) error {
+	c.drain.lock.Lock()
+	c.drain.draining = true
+	c.drain.lock.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		c.drain.inflight.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+	}
+	c.cancel()
+	<-finished
+	return ctx.Err()
+}
@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	c := &Client{}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+
+	require.True(t, c.drain.begin())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		c.drain.done()
+	}()
+	require.Nil(t, c.Drain(context.Background()))
+	require.False(t, c.drain.begin(), "no new drips after draining")
+	require.Nil(t, c.ctx.Err())
+}
+
+func TestDrainTimeout(t *testing.T) {
+	c := &Client{}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+
+	require.True(t, c.drain.begin())
+	// 模拟等待交易确认的发放，ctx 取消后才返回
+	go func() {
+		<-c.ctx.Done()
+		c.drain.done()
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.Equal(t, context.DeadlineExceeded, c.Drain(ctx))
+	require.NotNil(t, c.ctx.Err())
+}
@@ -0,0 +1,129 @@
+package errcode
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// Code 接口返回的错误码，客户端应当按 Code 而不是 msg 判断错误类型
+type Code string
+
+const (
+	OK Code = "OK"
+
+	InvalidRequest     Code = "INVALID_REQUEST"
+	InvalidAddress     Code = "INVALID_ADDRESS"
+	UnsupportedNetwork Code = "UNSUPPORTED_NETWORK"
+	InvalidAmount      Code = "INVALID_AMOUNT"
+	NotFound           Code = "NOT_FOUND"
+
+	Unauthorized  Code = "UNAUTHORIZED"
+	InvalidAPIKey Code = "INVALID_API_KEY"
+
+	Blocked           Code = "BLOCKED"
+	CaptchaFailed     Code = "CAPTCHA_FAILED"
+	OwnershipFailed   Code = "OWNERSHIP_PROOF_FAILED"
+	BalanceSufficient Code = "BALANCE_SUFFICIENT"
+	AuditTampered     Code = "AUDIT_TAMPERED"
+
+	CooldownActive      Code = "COOLDOWN_ACTIVE"
+	IPQuotaExceeded     Code = "IP_QUOTA_EXCEEDED"
+	APIKeyQuotaExceeded Code = "API_KEY_QUOTA_EXCEEDED"
+	RateLimited         Code = "RATE_LIMITED"
+
+	FaucetPaused     Code = "FAUCET_PAUSED"
+	FaucetEmpty      Code = "FAUCET_EMPTY"
+	BudgetExhausted  Code = "BUDGET_EXHAUSTED"
+	Overloaded       Code = "SERVICE_OVERLOADED"
+	ChainUnavailable Code = "CHAIN_UNAVAILABLE"
+	ShuttingDown     Code = "SHUTTING_DOWN"
+
+	Internal Code = "INTERNAL_ERROR"
+)
+
+// statuses 错误码对应的 http 状态码：请求本身有问题用 4xx，
+// 稍后重试即可成功的限制用 429，水龙头暂时无法服务用 503
+var statuses = map[Code]int{
+	OK:                  http.StatusOK,
+	InvalidRequest:      http.StatusBadRequest,
+	InvalidAddress:      http.StatusBadRequest,
+	UnsupportedNetwork:  http.StatusBadRequest,
+	InvalidAmount:       http.StatusBadRequest,
+	NotFound:            http.StatusNotFound,
+	Unauthorized:        http.StatusUnauthorized,
+	InvalidAPIKey:       http.StatusUnauthorized,
+	Blocked:             http.StatusForbidden,
+	CaptchaFailed:       http.StatusForbidden,
+	OwnershipFailed:     http.StatusForbidden,
+	BalanceSufficient:   http.StatusForbidden,
+	AuditTampered:       http.StatusConflict,
+	CooldownActive:      http.StatusTooManyRequests,
+	IPQuotaExceeded:     http.StatusTooManyRequests,
+	APIKeyQuotaExceeded: http.StatusTooManyRequests,
+	RateLimited:         http.StatusTooManyRequests,
+	FaucetPaused:        http.StatusServiceUnavailable,
+	FaucetEmpty:         http.StatusServiceUnavailable,
+	BudgetExhausted:     http.StatusServiceUnavailable,
+	Overloaded:          http.StatusServiceUnavailable,
+	ChainUnavailable:    http.StatusServiceUnavailable,
+	ShuttingDown:        http.StatusServiceUnavailable,
+	Internal:            http.StatusInternalServerError,
+}
+
+// Codes 全部错误码，按字母排序
+func Codes() []Code {
+	codes := make([]Code, 0, len(statuses))
+	for code := range statuses {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return codes[i] < codes[j]
+	})
+	return codes
+}
+
+// Status 错误码对应的 http 状态码
+func (c Code) Status() int {
+	if status, ok := statuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Error 带错误码的错误，RetryAfter 大于 0 时提示客户端多久之后重试
+type Error struct {
+	Code       Code
+	Msg        string
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// WithRetry 设置重试提示
+func (e *Error) WithRetry(d time.Duration) *Error {
+	e.RetryAfter = d
+	return e
+}
+
+func New(code Code, format string, args ...interface{}) *Error {
+	return &Error{Code: code, Msg: fmt.Sprintf(format, args...)}
+}
+
+// Wrap 以 err 的内容作为 msg
+func Wrap(code Code, err error) *Error {
+	return &Error{Code: code, Msg: err.Error(), Err: err}
+}
+
+// UntilNextUTCDay 距离下一个 UTC 零点的时长，按 UTC 自然日重置的配额用它作为重试提示
+func UntilNextUTCDay(now time.Time) time.Duration {
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour).Sub(now)
+}
@@ -0,0 +1,21 @@
+package errcode
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	for code := range statuses {
+		require.NotZero(t, code.Status())
+	}
+	require.Equal(t, http.StatusInternalServerError, Code("UNKNOWN").Status())
+}
+
+func TestUntilNextUTCDay(t *testing.T) {
+	now := time.Date(2023, 5, 1, 22, 30, 0, 0, time.UTC)
+	require.Equal(t, 90*time.Minute, UntilNextUTCDay(now))
+}
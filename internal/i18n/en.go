@@ -0,0 +1,31 @@
+package i18n
+
+var en = map[string]string{
+	MsgOK:       "ok",
+	MsgNetDrip:  "Each address can receive %v %s per claim",
+	MsgNetPause: "Claims on %s are paused, please try again later",
+
+	"INVALID_REQUEST":        "The request is malformed",
+	"INVALID_ADDRESS":        "The address is not a valid Ethereum address",
+	"UNSUPPORTED_NETWORK":    "The network is not supported",
+	"INVALID_AMOUNT":         "The amount is invalid",
+	"NOT_FOUND":              "The requested resource does not exist",
+	"UNAUTHORIZED":           "Authentication is required",
+	"INVALID_API_KEY":        "The API key is invalid or revoked",
+	"BLOCKED":                "The address or IP is blocked",
+	"CAPTCHA_FAILED":         "Captcha verification failed",
+	"OWNERSHIP_PROOF_FAILED": "Address ownership proof failed, please request a new challenge and sign it",
+	"BALANCE_SUFFICIENT":     "The address already holds enough test tokens",
+	"AUDIT_TAMPERED":         "The audit ledger failed verification",
+	"COOLDOWN_ACTIVE":        "The address has recently received test tokens, please try again later",
+	"IP_QUOTA_EXCEEDED":      "Too many claims from this IP today, please try again tomorrow",
+	"API_KEY_QUOTA_EXCEEDED": "The API key has used up today's quota",
+	"RATE_LIMITED":           "Too many requests, please slow down",
+	"FAUCET_PAUSED":          "The faucet is paused, please try again later",
+	"FAUCET_EMPTY":           "The faucet is running low on funds, please try again later",
+	"BUDGET_EXHAUSTED":       "The faucet has reached its dispense budget, please try again later",
+	"SERVICE_OVERLOADED":     "The faucet is busy, please try again later",
+	"CHAIN_UNAVAILABLE":      "The chain node is unavailable, please try again later",
+	"SHUTTING_DOWN":          "The faucet is restarting, please try again shortly",
+	"INTERNAL_ERROR":         "Internal error, please try again later",
+}
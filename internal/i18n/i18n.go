@@ -0,0 +1,97 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	EN = "en"
+	ZH = "zh"
+
+	// Default 请求没有指定或指定了不支持的语言时使用
+	Default = EN
+)
+
+// 错误码之外的提示文案
+const (
+	MsgOK       = "OK"
+	MsgNetDrip  = "NET_DRIP"
+	MsgNetPause = "NET_PAUSED"
+)
+
+// catalogues 按语言索引的文案，key 为错误码或上面的文案 id，value 可以带 fmt 占位符
+var catalogues = map[string]map[string]string{
+	EN: en,
+	ZH: zh,
+}
+
+// Supported 支持的语言
+func Supported() []string {
+	langs := make([]string, 0, len(catalogues))
+	for lang := range catalogues {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Message 查找 lang 下的文案，缺失时依次回退到默认语言和 id 本身
+func Message(lang string, id string, args ...interface{}) string {
+	format, ok := catalogues[lang][id]
+	if !ok {
+		format, ok = catalogues[Default][id]
+	}
+	if !ok {
+		return id
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Negotiate 选择响应语言：query 参数优先，其次按 Accept-Language 的权重从高到低匹配主语言标签
+func Negotiate(query string, acceptLanguage string) string {
+	if lang, ok := match(query); ok {
+		return lang
+	}
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		c := candidate{tag: strings.TrimSpace(fields[0]), q: 1}
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					c.q = q
+				}
+			}
+		}
+		if c.tag != "" && c.q > 0 {
+			candidates = append(candidates, c)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].q > candidates[j].q
+	})
+	for _, c := range candidates {
+		if lang, ok := match(c.tag); ok {
+			return lang
+		}
+	}
+	return Default
+}
+
+// match 按主语言标签匹配，zh-CN、zh-Hans-TW 都归到 zh
+func match(tag string) (string, bool) {
+	primary := strings.ToLower(strings.SplitN(strings.ReplaceAll(tag, "_", "-"), "-", 2)[0])
+	_, ok := catalogues[primary]
+	return primary, ok
+}
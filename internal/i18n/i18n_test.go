@@ -0,0 +1,43 @@
+package i18n
+
+import (
+	"faucet/internal/errcode"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCataloguesComplete(t *testing.T) {
+	for _, lang := range Supported() {
+		for _, code := range errcode.Codes() {
+			_, ok := catalogues[lang][string(code)]
+			require.True(t, ok, "%s missing %s", lang, code)
+		}
+		require.Len(t, catalogues[lang], len(catalogues[Default]), lang)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	require.Equal(t, "每个地址每次可领取 0.5 axm", Message(ZH, MsgNetDrip, 0.5, "axm"))
+	require.Equal(t, "ok", Message("fr", MsgOK))
+	require.Equal(t, "UNKNOWN", Message(ZH, "UNKNOWN"))
+}
+
+func TestNegotiate(t *testing.T) {
+	cases := []struct {
+		query  string
+		header string
+		lang   string
+	}{
+		{"", "", EN},
+		{"zh", "en-US", ZH},
+		{"fr", "zh-CN,zh;q=0.9", ZH},
+		{"", "fr-FR, en;q=0.5, zh;q=0.8", ZH},
+		{"", "zh-Hans-TW", ZH},
+		{"", "zh;q=0, en", EN},
+		{"", "ja", EN},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.lang, Negotiate(c.query, c.header), "%q %q", c.query, c.header)
+	}
+}
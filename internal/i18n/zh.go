@@ -0,0 +1,31 @@
+package i18n
+
+var zh = map[string]string{
+	MsgOK:       "成功",
+	MsgNetDrip:  "每个地址每次可领取 %v %s",
+	MsgNetPause: "%s 已暂停领取，请稍后再试",
+
+	"INVALID_REQUEST":        "请求格式错误",
+	"INVALID_ADDRESS":        "地址不是合法的以太坊地址",
+	"UNSUPPORTED_NETWORK":    "不支持该网络",
+	"INVALID_AMOUNT":         "数量不合法",
+	"NOT_FOUND":              "请求的资源不存在",
+	"UNAUTHORIZED":           "需要认证",
+	"INVALID_API_KEY":        "API key 无效或已被吊销",
+	"BLOCKED":                "地址或 IP 已被禁止领取",
+	"CAPTCHA_FAILED":         "人机验证失败",
+	"OWNERSHIP_PROOF_FAILED": "地址所有权验证失败，请重新获取挑战并签名",
+	"BALANCE_SUFFICIENT":     "该地址的测试币余额已经足够",
+	"AUDIT_TAMPERED":         "审计记录校验失败",
+	"COOLDOWN_ACTIVE":        "该地址近期已领取过测试币，请稍后再试",
+	"IP_QUOTA_EXCEEDED":      "该 IP 今日领取次数已达上限，请明天再试",
+	"API_KEY_QUOTA_EXCEEDED": "该 API key 今日额度已用完",
+	"RATE_LIMITED":           "请求过于频繁，请稍后再试",
+	"FAUCET_PAUSED":          "水龙头已暂停，请稍后再试",
+	"FAUCET_EMPTY":           "水龙头余额不足，请稍后再试",
+	"BUDGET_EXHAUSTED":       "水龙头已达到发放额度上限，请稍后再试",
+	"SERVICE_OVERLOADED":     "服务繁忙，请稍后再试",
+	"CHAIN_UNAVAILABLE":      "链节点不可用，请稍后再试",
+	"SHUTTING_DOWN":          "服务正在重启，请稍后再试",
+	"INTERNAL_ERROR":         "内部错误，请稍后再试",
+}
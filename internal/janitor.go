@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"faucet/internal/cooldown"
+	"faucet/internal/loggers"
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var _ Lifecycle = (*Janitor)(nil)
+
+// Janitor 定期清理超过保留期的领取记录和 ip 计数，并整理对应的 leveldb 区间
+type Janitor struct {
+	store      store.Store
+	cfg        repo.Retention
+	claims     time.Duration
+	archiveDir string
+	now        func() time.Time
+	logger     logrus.FieldLogger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// archivedClaim 归档文件中的一行
+type archivedClaim struct {
+	Net     string `json:"net"`
+	Type    string `json:"type"`
+	Address string `json:"address"`
+	*store.Claim
+}
+
+// SweepResult 一次清理的结果
+type SweepResult struct {
+	Claims   int
+	IPQuotas int
+}
+
+func NewJanitor(s store.Store, cfg *repo.Config) *Janitor {
+	// 保留期短于冷却窗口会让地址提前解除限制
+	claims := cfg.Retention.Claims
+	tokens := []string{nativeToken}
+	for token := range cfg.Axiom.TokenCooldowns {
+		tokens = append(tokens, token)
+	}
+	for _, token := range tokens {
+		policies := []repo.Cooldown{cfg.Axiom.CooldownFor(token, nil)}
+		for i := range cfg.Axiom.Tiers {
+			policies = append(policies, cfg.Axiom.CooldownFor(token, &cfg.Axiom.Tiers[i]))
+		}
+		for _, policy := range policies {
+			if retention := cooldown.Retention(policy); retention > claims {
+				claims = retention
+			}
+		}
+	}
+	archiveDir := cfg.Retention.ArchiveDir
+	if !filepath.IsAbs(archiveDir) {
+		archiveDir = filepath.Join(cfg.RepoRoot, archiveDir)
+	}
+	return &Janitor{
+		store:      s,
+		cfg:        cfg.Retention,
+		claims:     claims,
+		archiveDir: archiveDir,
+		now:        time.Now,
+		logger:     loggers.Logger(loggers.ApiServer),
+	}
+}
+
+func (j *Janitor) Start() error {
+	if !j.cfg.Enable {
+		return nil
+	}
+	if j.cfg.Interval <= 0 {
+		return fmt.Errorf("retention interval must be positive: %s", j.cfg.Interval)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	j.cancel = cancel
+	j.done = make(chan struct{})
+	go j.loop(ctx)
+	j.logger.Infof("janitor started, keep claims for %s and ip quotas for %s", j.claims, j.cfg.IPQuotas)
+	return nil
+}
+
+func (j *Janitor) Stop() error {
+	if j.cancel == nil {
+		return nil
+	}
+	j.cancel()
+	<-j.done
+	return nil
+}
+
+func (j *Janitor) loop(ctx context.Context) {
+	defer close(j.done)
+	ticker := time.NewTicker(j.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		j.run()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (j *Janitor) run() {
+	res, err := j.Sweep()
+	if err != nil {
+		j.logger.Errorf("janitor sweep: %s", err)
+		return
+	}
+	if res.Claims > 0 || res.IPQuotas > 0 {
+		j.logger.Infof("janitor removed %d expired claims and %d ip quotas", res.Claims, res.IPQuotas)
+	}
+}
+
+// Sweep 删除过期的记录，开启归档时先写入归档文件，最后整理被清理的区间
+func (j *Janitor) Sweep() (*SweepResult, error) {
+	now := j.now()
+	cutoff := now.Add(-j.claims).Unix()
+	var expired []*archivedClaim
+	err := j.store.Claims().Scan(func(net string, typ string, address string, claim *store.Claim) bool {
+		if claim.SendTxTime < cutoff {
+			expired = append(expired, &archivedClaim{Net: net, Type: typ, Address: address, Claim: claim})
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if j.cfg.Archive && len(expired) > 0 {
+		if err := j.archive(now, expired); err != nil {
+			return nil, err
+		}
+	}
+	for _, claim := range expired {
+		if err := j.store.Claims().Delete(claim.Net, claim.Type, claim.Address); err != nil {
+			return nil, err
+		}
+	}
+
+	quotas, err := j.store.IPQuotas().Prune(now.Add(-j.cfg.IPQuotas))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(expired) > 0 {
+		if err := j.store.Claims().Compact(); err != nil {
+			return nil, err
+		}
+	}
+	if quotas > 0 {
+		if err := j.store.IPQuotas().Compact(); err != nil {
+			return nil, err
+		}
+	}
+	return &SweepResult{Claims: len(expired), IPQuotas: quotas}, nil
+}
+
+func (j *Janitor) archive(now time.Time, claims []*archivedClaim) error {
+	if err := os.MkdirAll(j.archiveDir, 0755); err != nil {
+		return fmt.Errorf("create archive dir: %w", err)
+	}
+	path := filepath.Join(j.archiveDir, fmt.Sprintf("claims-%s.jsonl", now.Format("2006-01-02")))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open archive: %w", err)
+	}
+	defer f.Close()
+	enc := json.NewEncoder(f)
+	for _, claim := range claims {
+		if err := enc.Encode(claim); err != nil {
+			return fmt.Errorf("write archive: %w", err)
+		}
+	}
+	return f.Sync()
+}
@@ -0,0 +1,86 @@
+package internal
+
+import (
+	"faucet/internal/loggers"
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	loggers.InitializeLogger(&repo.Config{Log: repo.Log{Module: repo.LogModule{ApiServer: "info"}}})
+}
+
+func TestJanitorSweep(t *testing.T) {
+	now := time.Date(2023, 8, 10, 12, 0, 0, 0, time.Local)
+	s := store.New(store.NewMemory())
+	cfg := &repo.Config{
+		RepoRoot: t.TempDir(),
+		Axiom: repo.AXIOM{
+			Cooldown: repo.Cooldown{Period: 24 * time.Hour, MaxClaims: 1, Mode: "sliding"},
+			Tiers:    []repo.Tier{{Name: "partner", Cooldown: repo.Cooldown{Period: 72 * time.Hour}}},
+		},
+		Retention: repo.Retention{
+			Enable: true,
+			// 短于最长冷却时间，实际按 72h 保留
+			Claims:     time.Hour,
+			IPQuotas:   48 * time.Hour,
+			Archive:    true,
+			ArchiveDir: "archive",
+		},
+	}
+	j := NewJanitor(s, cfg)
+	j.now = func() time.Time { return now }
+
+	require.Nil(t, s.Claims().Put("axm", nativeToken, "0x01", &store.Claim{SendTxTime: now.Add(-73 * time.Hour).Unix(), TxHash: "0xa"}))
+	require.Nil(t, s.Claims().Put("axm", nativeToken, "0x02", &store.Claim{SendTxTime: now.Add(-25 * time.Hour).Unix(), TxHash: "0xb"}))
+	_, err := s.IPQuotas().Incr("axm", "10.0.0.1", now.Add(-72*time.Hour))
+	require.Nil(t, err)
+	_, err = s.IPQuotas().Incr("axm", "10.0.0.1", now)
+	require.Nil(t, err)
+
+	res, err := j.Sweep()
+	require.Nil(t, err)
+	require.Equal(t, &SweepResult{Claims: 1, IPQuotas: 1}, res)
+
+	claim, err := s.Claims().Get("axm", nativeToken, "0x01")
+	require.Nil(t, err)
+	require.Nil(t, claim)
+	claim, err = s.Claims().Get("axm", nativeToken, "0x02")
+	require.Nil(t, err)
+	require.NotNil(t, claim)
+	count, err := s.IPQuotas().Count("axm", "10.0.0.1", now)
+	require.Nil(t, err)
+	require.Equal(t, 1, count)
+
+	data, err := ioutil.ReadFile(filepath.Join(cfg.RepoRoot, "archive", "claims-2023-08-10.jsonl"))
+	require.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 1)
+	require.Contains(t, lines[0], `"address":"0x01"`)
+	require.Contains(t, lines[0], `"txHash":"0xa"`)
+
+	res, err = j.Sweep()
+	require.Nil(t, err)
+	require.Equal(t, &SweepResult{}, res)
+}
+
+func TestJanitorStartStop(t *testing.T) {
+	j := NewJanitor(store.New(store.NewMemory()), &repo.Config{
+		Retention: repo.Retention{Enable: true, Interval: time.Millisecond},
+	})
+	require.Nil(t, j.Start())
+	time.Sleep(5 * time.Millisecond)
+	require.Nil(t, j.Stop())
+
+	// 未开启时 Start/Stop 都不做任何事
+	j = NewJanitor(store.New(store.NewMemory()), &repo.Config{})
+	require.Nil(t, j.Start())
+	require.Nil(t, j.Stop())
+}
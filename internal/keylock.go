@@ -0,0 +1,40 @@
+package internal
+
+import "sync"
+
+// keyLock 按 key 互斥，没有持有者的 key 随即删除，零值可以直接使用
+type keyLock struct {
+	lock  sync.Mutex
+	locks map[string]*keyLockEntry
+}
+
+type keyLockEntry struct {
+	sync.Mutex
+	refs int
+}
+
+// Lock 阻塞到取得 key 的锁，返回释放函数
+func (l *keyLock) Lock(key string) func() {
+	l.lock.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*keyLockEntry)
+	}
+	e, ok := l.locks[key]
+	if !ok {
+		e = &keyLockEntry{}
+		l.locks[key] = e
+	}
+	e.refs++
+	l.lock.Unlock()
+
+	e.Lock()
+	return func() {
+		e.Unlock()
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		e.refs--
+		if e.refs == 0 {
+			delete(l.locks, key)
+		}
+	}
+}
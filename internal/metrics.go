@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"faucet/internal/audit"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+var _ Lifecycle = (*Metrics)(nil)
+
+// metricsBuffer 订阅审计账本的缓冲，写入过快时丢弃的记录不计数
+const metricsBuffer = 1024
+
+type claimLabels struct {
+	net    string
+	status string
+}
+
+// Metrics 在单独端口上以 prometheus 文本格式提供 /metrics：
+// 启动以来按 net 和状态统计的领取次数、待确认交易数和水龙头余额
+type Metrics struct {
+	client  *Client
+	port    string
+	balance func(net string) (float64, error)
+	logger  logrus.FieldLogger
+
+	lock   sync.Mutex
+	claims map[claimLabels]uint64
+
+	server *http.Server
+	cancel func()
+	done   chan struct{}
+}
+
+func NewMetrics(c *Client) *Metrics {
+	return &Metrics{
+		client:  c,
+		port:    c.Config.Metrics.Port,
+		balance: c.Balance,
+		logger:  c.logger,
+		claims:  make(map[claimLabels]uint64),
+	}
+}
+
+func (m *Metrics) Start() error {
+	ln, err := net.Listen("tcp", fmt.Sprintf(":%s", m.port))
+	if err != nil {
+		return fmt.Errorf("metrics listen: %w", err)
+	}
+	events, cancel := m.client.Audit.Subscribe(metricsBuffer)
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		for entry := range events {
+			m.observe(entry)
+		}
+	}()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		m.Write(w)
+	})
+	m.server = &http.Server{Addr: ln.Addr().String(), Handler: mux}
+	go func() {
+		m.logger.Infof("start metrics on %s", m.server.Addr)
+		if err := m.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			m.logger.Errorf("metrics stopped: %s", err)
+		}
+	}()
+	return nil
+}
+
+func (m *Metrics) Stop() error {
+	if m.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), m.client.Config.Shutdown.Timeout)
+	defer cancel()
+	if err := m.server.Shutdown(ctx); err != nil {
+		m.server.Close()
+	}
+	m.cancel()
+	<-m.done
+	return nil
+}
+
+func (m *Metrics) observe(entry *audit.Entry) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.claims[claimLabels{net: entry.Net, status: entry.Status}]++
+}
+
+// Write 输出当前的指标，查询余额或待确认交易失败时跳过对应的指标
+func (m *Metrics) Write(w io.Writer) {
+	m.lock.Lock()
+	labels := make([]claimLabels, 0, len(m.claims))
+	for l := range m.claims {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].net != labels[j].net {
+			return labels[i].net < labels[j].net
+		}
+		return labels[i].status < labels[j].status
+	})
+	fmt.Fprintln(w, "# HELP faucet_claims_total Claim attempts recorded in the audit ledger since start.")
+	fmt.Fprintln(w, "# TYPE faucet_claims_total counter")
+	for _, l := range labels {
+		fmt.Fprintf(w, "faucet_claims_total{net=%q,status=%q} %d\n", l.net, l.status, m.claims[l])
+	}
+	m.lock.Unlock()
+
+	if txs, err := m.client.PendingTxs(); err != nil {
+		m.logger.Warnf("metrics: list pending txs: %s", err)
+	} else {
+		fmt.Fprintln(w, "# HELP faucet_pending_txs Broadcast claim transactions waiting for confirmation.")
+		fmt.Fprintln(w, "# TYPE faucet_pending_txs gauge")
+		fmt.Fprintf(w, "faucet_pending_txs %d\n", len(txs))
+	}
+
+	fmt.Fprintln(w, "# HELP faucet_balance Faucet account balance in ether.")
+	fmt.Fprintln(w, "# TYPE faucet_balance gauge")
+	for _, net := range m.client.Nets() {
+		balance, err := m.balance(net)
+		if err != nil {
+			m.logger.Warnf("metrics: get balance of %s: %s", net, err)
+			continue
+		}
+		fmt.Fprintf(w, "faucet_balance{net=%q} %g\n", net, balance)
+	}
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"bytes"
+	"faucet/internal/audit"
+	"faucet/internal/budget"
+	"faucet/internal/loggers"
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsWrite(t *testing.T) {
+	s := store.New(store.NewMemory())
+	c := &Client{
+		Config:  &repo.Config{},
+		store:   s,
+		Audit:   audit.New(s.Audit()),
+		budgets: map[string]*budget.Budget{"axm": budget.New(s.Budgets(), "axm", repo.Budget{})},
+		logger:  loggers.Logger(loggers.ApiServer),
+	}
+	require.Nil(t, s.Jobs().Put(&PendingTx{Net: "axm", TxHash: "0x1"}))
+	m := NewMetrics(c)
+	m.balance = func(net string) (float64, error) { return 12.5, nil }
+	for _, status := range []string{audit.StatusConfirmed, audit.StatusConfirmed, audit.StatusRejected} {
+		m.observe(&audit.Entry{Net: "axm", Status: status})
+	}
+
+	var buf bytes.Buffer
+	m.Write(&buf)
+	out := buf.String()
+	require.Contains(t, out, `faucet_claims_total{net="axm",status="confirmed"} 2`)
+	require.Contains(t, out, `faucet_claims_total{net="axm",status="rejected"} 1`)
+	require.Contains(t, out, "faucet_pending_txs 1\n")
+	require.Contains(t, out, `faucet_balance{net="axm"} 12.5`)
+}
@@ -0,0 +1,179 @@
+package internal
+
+import (
+	"faucet/internal/loggers"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+var _ Lifecycle = (*Node)(nil)
+
+// ServiceStatus 服务在 Node 中的状态
+type ServiceStatus string
+
+const (
+	StatusRegistered ServiceStatus = "registered"
+	StatusRunning    ServiceStatus = "running"
+	StatusFailed     ServiceStatus = "failed"
+	StatusStopped    ServiceStatus = "stopped"
+)
+
+// ServiceInfo 一个服务的当前状态，Error 为启动或停止失败的原因
+type ServiceInfo struct {
+	Name      string        `json:"name"`
+	DependsOn []string      `json:"dependsOn,omitempty"`
+	Status    ServiceStatus `json:"status"`
+	Error     string        `json:"error,omitempty"`
+}
+
+type service struct {
+	name      string
+	deps      []string
+	lifecycle Lifecycle
+	status    ServiceStatus
+	err       error
+}
+
+// Node 管理水龙头的全部服务：依赖的服务先启动，停止时按启动的相反顺序进行，
+// 任何一个服务启动失败都会停止已经启动的服务并返回错误
+type Node struct {
+	// lock 串行化注册、启动和停止；statusLock 只保护状态，查询状态不会被耗时的停止阻塞
+	lock       sync.Mutex
+	statusLock sync.RWMutex
+	services   []*service
+	byName     map[string]*service
+	// started 实际启动成功的顺序，Stop 按相反顺序停止
+	started []*service
+	logger  logrus.FieldLogger
+}
+
+func NewNode() *Node {
+	return &Node{
+		byName: make(map[string]*service),
+		logger: loggers.Logger(loggers.ApiServer),
+	}
+}
+
+// RegisterLifecycle 注册服务，deps 为需要先于它启动的服务名，必须在 Start 之前调用
+func (n *Node) RegisterLifecycle(name string, lifecycle Lifecycle, deps ...string) error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	if _, ok := n.byName[name]; ok {
+		return fmt.Errorf("service %s already registered", name)
+	}
+	s := &service{name: name, deps: deps, lifecycle: lifecycle, status: StatusRegistered}
+	n.statusLock.Lock()
+	n.services = append(n.services, s)
+	n.statusLock.Unlock()
+	n.byName[name] = s
+	return nil
+}
+
+// order 按依赖关系排序，没有依赖关系的服务保持注册顺序
+func (n *Node) order() ([]*service, error) {
+	var (
+		sorted   []*service
+		visiting = make(map[string]bool)
+		visited  = make(map[string]bool)
+		visit    func(s *service, path []string) error
+	)
+	visit = func(s *service, path []string) error {
+		if visited[s.name] {
+			return nil
+		}
+		path = append(path, s.name)
+		if visiting[s.name] {
+			return fmt.Errorf("service dependency cycle: %s", strings.Join(path, " -> "))
+		}
+		visiting[s.name] = true
+		for _, dep := range s.deps {
+			d, ok := n.byName[dep]
+			if !ok {
+				return fmt.Errorf("service %s depends on unregistered service %s", s.name, dep)
+			}
+			if err := visit(d, path); err != nil {
+				return err
+			}
+		}
+		visited[s.name] = true
+		sorted = append(sorted, s)
+		return nil
+	}
+	for _, s := range n.services {
+		if err := visit(s, nil); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
+
+func (n *Node) Start() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	sorted, err := n.order()
+	if err != nil {
+		return err
+	}
+	for _, s := range sorted {
+		if err := s.lifecycle.Start(); err != nil {
+			n.setStatus(s, StatusFailed, err)
+			n.logger.Errorf("start service %s: %s", s.name, err)
+			n.stopStarted()
+			return fmt.Errorf("start service %s: %w", s.name, err)
+		}
+		n.setStatus(s, StatusRunning, nil)
+		n.started = append(n.started, s)
+		n.logger.Infof("service %s started", s.name)
+	}
+	return nil
+}
+
+func (n *Node) Stop() error {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+	return n.stopStarted()
+}
+
+// stopStarted 按启动的相反顺序停止，某个服务停止失败不影响其余服务，返回第一个错误
+func (n *Node) stopStarted() error {
+	var first error
+	for i := len(n.started) - 1; i >= 0; i-- {
+		s := n.started[i]
+		if err := s.lifecycle.Stop(); err != nil {
+			n.setStatus(s, StatusFailed, err)
+			n.logger.Errorf("stop service %s: %s", s.name, err)
+			if first == nil {
+				first = fmt.Errorf("stop service %s: %w", s.name, err)
+			}
+			continue
+		}
+		n.setStatus(s, StatusStopped, nil)
+		n.logger.Infof("service %s stopped", s.name)
+	}
+	n.started = nil
+	return first
+}
+
+func (n *Node) setStatus(s *service, status ServiceStatus, err error) {
+	n.statusLock.Lock()
+	defer n.statusLock.Unlock()
+	s.status, s.err = status, err
+}
+
+// Status 按注册顺序返回每个服务的状态
+func (n *Node) Status() []*ServiceInfo {
+	n.statusLock.RLock()
+	defer n.statusLock.RUnlock()
+	infos := make([]*ServiceInfo, 0, len(n.services))
+	for _, s := range n.services {
+		info := &ServiceInfo{Name: s.name, DependsOn: s.deps, Status: s.status}
+		if s.err != nil {
+			info.Error = s.err.Error()
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
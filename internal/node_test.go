@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeService struct {
+	name     string
+	events   *[]string
+	startErr error
+}
+
+func (f *fakeService) Start() error {
+	if f.startErr != nil {
+		return f.startErr
+	}
+	*f.events = append(*f.events, "start "+f.name)
+	return nil
+}
+
+func (f *fakeService) Stop() error {
+	*f.events = append(*f.events, "stop "+f.name)
+	return nil
+}
+
+func TestNodeOrder(t *testing.T) {
+	var events []string
+	n := NewNode()
+	require.Nil(t, n.RegisterLifecycle("api", &fakeService{name: "api", events: &events}, "client", "janitor"))
+	require.Nil(t, n.RegisterLifecycle("janitor", &fakeService{name: "janitor", events: &events}, "client"))
+	require.Nil(t, n.RegisterLifecycle("client", &fakeService{name: "client", events: &events}))
+	require.NotNil(t, n.RegisterLifecycle("client", &fakeService{name: "client", events: &events}))
+
+	require.Nil(t, n.Start())
+	for _, info := range n.Status() {
+		require.Equal(t, StatusRunning, info.Status, info.Name)
+	}
+	require.Nil(t, n.Stop())
+	require.Equal(t, []string{
+		"start client", "start janitor", "start api",
+		"stop api", "stop janitor", "stop client",
+	}, events)
+	for _, info := range n.Status() {
+		require.Equal(t, StatusStopped, info.Status, info.Name)
+	}
+}
+
+func TestNodeStartFailure(t *testing.T) {
+	var events []string
+	n := NewNode()
+	require.Nil(t, n.RegisterLifecycle("client", &fakeService{name: "client", events: &events}))
+	require.Nil(t, n.RegisterLifecycle("api", &fakeService{name: "api", events: &events, startErr: errors.New("address in use")}, "client"))
+	require.Nil(t, n.RegisterLifecycle("grpc", &fakeService{name: "grpc", events: &events}, "api"))
+
+	err := n.Start()
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "api")
+	require.Equal(t, []string{"start client", "stop client"}, events)
+
+	status := n.Status()
+	require.Equal(t, StatusStopped, status[0].Status)
+	require.Equal(t, StatusFailed, status[1].Status)
+	require.Equal(t, "address in use", status[1].Error)
+	require.Equal(t, StatusRegistered, status[2].Status)
+}
+
+func TestNodeDependencyErrors(t *testing.T) {
+	var events []string
+	n := NewNode()
+	require.Nil(t, n.RegisterLifecycle("a", &fakeService{name: "a", events: &events}, "b"))
+	require.Nil(t, n.RegisterLifecycle("b", &fakeService{name: "b", events: &events}, "a"))
+	err := n.Start()
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "a -> b -> a")
+
+	n = NewNode()
+	require.Nil(t, n.RegisterLifecycle("a", &fakeService{name: "a", events: &events}, "missing"))
+	require.NotNil(t, n.Start())
+	require.Empty(t, events)
+}
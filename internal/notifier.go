@@ -0,0 +1,159 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"faucet/internal/audit"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var _ Lifecycle = (*Notifier)(nil)
+
+const (
+	// notifierBuffer 订阅审计账本的缓冲，通知发送过慢时丢弃新的失败记录
+	notifierBuffer = 64
+	// notifierTimeout 单次 webhook 请求的超时
+	notifierTimeout = 10 * time.Second
+)
+
+// Notifier 余额低于阈值或领取交易上链失败时向 webhook 发送通知，
+// 余额告警在回升到阈值以上之前只发送一次
+type Notifier struct {
+	client     *Client
+	webhookURL string
+	lowBalance float64
+	interval   time.Duration
+	balance    func(net string) (float64, error)
+	httpClient *http.Client
+	logger     logrus.FieldLogger
+
+	// low 已经发送过余额告警的 net
+	low map[string]bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type notification struct {
+	Text string `json:"text"`
+}
+
+func NewNotifier(c *Client) *Notifier {
+	cfg := c.Config.Notifier
+	return &Notifier{
+		client:     c,
+		webhookURL: cfg.WebhookURL,
+		lowBalance: cfg.LowBalance,
+		interval:   cfg.Interval,
+		balance:    c.Balance,
+		httpClient: &http.Client{Timeout: notifierTimeout},
+		logger:     c.logger,
+		low:        make(map[string]bool),
+	}
+}
+
+func (n *Notifier) Start() error {
+	events, unsubscribe := n.client.Audit.Subscribe(notifierBuffer)
+	ctx, cancel := context.WithCancel(context.Background())
+	n.cancel = func() {
+		cancel()
+		unsubscribe()
+	}
+	n.done = make(chan struct{})
+	go n.loop(ctx, events)
+	return nil
+}
+
+func (n *Notifier) Stop() error {
+	if n.cancel == nil {
+		return nil
+	}
+	n.cancel()
+	<-n.done
+	return nil
+}
+
+func (n *Notifier) loop(ctx context.Context, events <-chan *audit.Entry) {
+	defer close(n.done)
+	ticker := time.NewTicker(n.interval)
+	defer ticker.Stop()
+	for {
+		n.CheckBalance(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case entry, ok := <-events:
+			if !ok {
+				return
+			}
+			n.Observe(ctx, entry)
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckBalance 余额首次低于 low_balance 时发送告警
+func (n *Notifier) CheckBalance(ctx context.Context) {
+	if n.lowBalance <= 0 {
+		return
+	}
+	for _, net := range n.client.Nets() {
+		balance, err := n.balance(net)
+		if err != nil {
+			n.logger.Warnf("notifier: get balance of %s: %s", net, err)
+			continue
+		}
+		if balance >= n.lowBalance {
+			n.low[net] = false
+			continue
+		}
+		if n.low[net] {
+			continue
+		}
+		if n.send(ctx, fmt.Sprintf("faucet balance on %s is %g, below %g", net, balance, n.lowBalance)) {
+			n.low[net] = true
+		}
+	}
+}
+
+// Observe 已经发送的领取交易执行失败或被丢弃时发送通知，发送前被拒绝的请求不通知
+func (n *Notifier) Observe(ctx context.Context, entry *audit.Entry) {
+	if entry.Decision != audit.DecisionAllow || entry.Status != audit.StatusFailed {
+		return
+	}
+	text := fmt.Sprintf("claim of %g on %s to %s failed", entry.Amount, entry.Net, entry.Address)
+	if entry.TxHash != "" {
+		text += ", tx " + entry.TxHash
+	}
+	if entry.Reason != "" {
+		text += ": " + entry.Reason
+	}
+	n.send(ctx, text)
+}
+
+// send 发送失败只记录日志
+func (n *Notifier) send(ctx context.Context, text string) bool {
+	body, _ := json.Marshal(&notification{Text: text})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.webhookURL, bytes.NewReader(body))
+	if err != nil {
+		n.logger.Errorf("notifier: %s", err)
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := n.httpClient.Do(req)
+	if err != nil {
+		n.logger.Warnf("notifier: post webhook: %s", err)
+		return false
+	}
+	res.Body.Close()
+	if res.StatusCode >= http.StatusMultipleChoices {
+		n.logger.Warnf("notifier: webhook responded %s", res.Status)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"faucet/internal/audit"
+	"faucet/internal/budget"
+	"faucet/internal/loggers"
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifier(t *testing.T) {
+	var texts []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg notification
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&msg))
+		texts = append(texts, msg.Text)
+	}))
+	defer hook.Close()
+
+	s := store.New(store.NewMemory())
+	c := &Client{
+		Config: &repo.Config{Notifier: repo.Notifier{
+			Enable: true, WebhookURL: hook.URL, LowBalance: 10, Interval: time.Minute,
+		}},
+		Audit:   audit.New(s.Audit()),
+		budgets: map[string]*budget.Budget{"axm": budget.New(s.Budgets(), "axm", repo.Budget{})},
+		logger:  loggers.Logger(loggers.ApiServer),
+	}
+	n := NewNotifier(c)
+	balance := 20.0
+	n.balance = func(net string) (float64, error) { return balance, nil }
+	ctx := context.Background()
+
+	n.CheckBalance(ctx)
+	require.Empty(t, texts)
+	// 低于阈值只告警一次，回升后再次低于阈值重新告警
+	balance = 5
+	n.CheckBalance(ctx)
+	n.CheckBalance(ctx)
+	require.Len(t, texts, 1)
+	balance = 20
+	n.CheckBalance(ctx)
+	balance = 5
+	n.CheckBalance(ctx)
+	require.Len(t, texts, 2)
+	require.Contains(t, texts[0], "below 10")
+
+	n.Observe(ctx, &audit.Entry{Net: "axm", Decision: audit.DecisionDeny, Status: audit.StatusRejected})
+	n.Observe(ctx, &audit.Entry{Net: "axm", Address: "0xabc", Decision: audit.DecisionAllow, Status: audit.StatusFailed, TxHash: "0x1", Amount: 0.5})
+	require.Len(t, texts, 3)
+	require.Contains(t, texts[2], "0xabc")
+	require.Contains(t, texts[2], "0x1")
+}
@@ -0,0 +1,291 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// initialisms 生成 Go 标识符时整体大写的单词
+var initialisms = map[string]bool{
+	"acl": true, "api": true, "id": true, "ip": true, "json": true, "url": true, "http": true,
+}
+
+// GenerateClient 按 OpenAPI 文档生成 Go 客户端的类型和接口方法，
+// 生成的方法依赖同一个包中手写的 Client.do 发送请求和解析响应
+func (d *Document) GenerateClient(pkg string) ([]byte, error) {
+	g := &generator{doc: d}
+	if err := g.types(); err != nil {
+		return nil, err
+	}
+	if err := g.operations(); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by internal/openapi/gen from openapi.json. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+	out.WriteString("import (\n\t\"context\"\n")
+	if g.usesJSON {
+		out.WriteString("\t\"encoding/json\"\n")
+	}
+	if g.usesURL {
+		out.WriteString("\t\"net/url\"\n")
+	}
+	out.WriteString(")\n")
+	out.Write(g.buf.Bytes())
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated client: %w", err)
+	}
+	return src, nil
+}
+
+type generator struct {
+	doc      *Document
+	buf      bytes.Buffer
+	usesJSON bool
+	usesURL  bool
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// types 为 components 中的每个 object schema 生成结构体
+func (g *generator) types() error {
+	for _, name := range sortedKeys(g.doc.Components.Schemas) {
+		schema := g.doc.Components.Schemas[name]
+		if schema.Type != "object" {
+			continue
+		}
+		if err := g.structType(name, schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) structType(name string, schema *Schema) error {
+	g.printf("\n")
+	g.comment("", name, schema.Description)
+	g.printf("type %s struct {\n", name)
+	for _, prop := range sortedKeys(schema.Properties) {
+		typ, err := g.goType(schema.Properties[prop])
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", name, prop, err)
+		}
+		tag := prop
+		if !contains(schema.Required, prop) {
+			tag += ",omitempty"
+		}
+		resolved, _ := g.doc.Schema(schema.Properties[prop])
+		g.comment("\t", goName(prop), resolved.Description)
+		g.printf("\t%s %s `json:\"%s\"`\n", goName(prop), typ, tag)
+	}
+	g.printf("}\n")
+	return nil
+}
+
+func (g *generator) goType(s *Schema) (string, error) {
+	resolved, name := g.doc.Schema(s)
+	if resolved == nil {
+		return "", fmt.Errorf("unresolved schema %s", s.Ref)
+	}
+	if name != "" && resolved.Type == "object" {
+		return "*" + name, nil
+	}
+	switch resolved.Type {
+	case "string":
+		return "string", nil
+	case "boolean":
+		return "bool", nil
+	case "number":
+		return "float64", nil
+	case "integer":
+		switch resolved.Format {
+		case "int64", "uint64":
+			return resolved.Format, nil
+		}
+		return "int", nil
+	case "array":
+		item, err := g.goType(resolved.Items)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + item, nil
+	case "object":
+		return "map[string]interface{}", nil
+	case "":
+		g.usesJSON = true
+		return "json.RawMessage", nil
+	}
+	return "", fmt.Errorf("unsupported schema type %s", resolved.Type)
+}
+
+// operations 为每个接口生成方法，按 operationId 排序
+func (g *generator) operations() error {
+	routes := g.doc.Routes()
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Operation.OperationID < routes[j].Operation.OperationID
+	})
+	for _, route := range routes {
+		if err := g.operation(route); err != nil {
+			return fmt.Errorf("%s %s: %w", route.Method, route.Path, err)
+		}
+	}
+	return nil
+}
+
+func (g *generator) operation(route *Route) error {
+	op := route.Operation
+	name := goName(op.OperationID)
+	args := []string{"ctx context.Context"}
+	var pathParams, queryParams []*Parameter
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			pathParams = append(pathParams, p)
+			args = append(args, p.Name+" string")
+		case "query":
+			queryParams = append(queryParams, p)
+		default:
+			return fmt.Errorf("unsupported parameter location %s", p.In)
+		}
+	}
+
+	if len(queryParams) > 0 {
+		g.printf("\n// %sParams are the query parameters of %s\n", name, name)
+		g.printf("type %sParams struct {\n", name)
+		for _, p := range queryParams {
+			typ, err := g.goType(p.Schema)
+			if err != nil {
+				return err
+			}
+			if typ != "string" {
+				return fmt.Errorf("query parameter %s must be a string", p.Name)
+			}
+			g.comment("\t", goName(p.Name), p.Description)
+			g.printf("\t%s string\n", goName(p.Name))
+		}
+		g.printf("}\n")
+		args = append(args, fmt.Sprintf("params *%sParams", name))
+	}
+
+	body, bodyName := g.doc.RequestBody(op)
+	if body != nil {
+		media, ok := body.Content["application/json"]
+		if !ok {
+			return fmt.Errorf("request body must be application/json")
+		}
+		if bodyName == "" {
+			bodyName = name + "Request"
+		}
+		if _, exists := g.doc.Components.Schemas[bodyName]; exists {
+			return fmt.Errorf("request type %s conflicts with a schema", bodyName)
+		}
+		if !g.declared(bodyName) {
+			schema, _ := g.doc.Schema(media.Schema)
+			if err := g.structType(bodyName, schema); err != nil {
+				return err
+			}
+		}
+		args = append(args, fmt.Sprintf("body *%s", bodyName))
+	}
+
+	data, err := g.dataType(op)
+	if err != nil {
+		return err
+	}
+	g.printf("\n")
+	g.comment("", name, op.Summary)
+	if data == "" {
+		g.printf("func (c *Client) %s(%s) error {\n", name, strings.Join(args, ", "))
+	} else {
+		g.printf("func (c *Client) %s(%s) (%s, error) {\n", name, strings.Join(args, ", "), data)
+	}
+
+	path := fmt.Sprintf("%q", route.Path)
+	g.usesURL = g.usesURL || len(pathParams) > 0 || len(queryParams) > 0
+	for _, p := range pathParams {
+		path = strings.Replace(path, "{"+p.Name+"}", fmt.Sprintf("\" + url.PathEscape(%s) + \"", p.Name), 1)
+	}
+	path = strings.TrimSuffix(strings.TrimPrefix(path, "\"\" + "), " + \"\"")
+	query := "nil"
+	if len(queryParams) > 0 {
+		query = "query"
+		g.printf("\tquery := url.Values{}\n\tif params != nil {\n")
+		for _, p := range queryParams {
+			field := goName(p.Name)
+			g.printf("\t\tif params.%s != \"\" {\n\t\t\tquery.Set(%q, params.%s)\n\t\t}\n", field, p.Name, field)
+		}
+		g.printf("\t}\n")
+	}
+	reqBody := "nil"
+	if body != nil {
+		reqBody = "body"
+	}
+	if data == "" {
+		g.printf("\treturn c.do(ctx, %q, %s, %s, %s, nil)\n}\n", route.Method, path, query, reqBody)
+		return nil
+	}
+	g.printf("\tout := &%s{}\n", strings.TrimPrefix(data, "*"))
+	g.printf("\tif err := c.do(ctx, %q, %s, %s, %s, out); err != nil {\n\t\treturn nil, err\n\t}\n", route.Method, path, query, reqBody)
+	g.printf("\treturn out, nil\n}\n")
+	return nil
+}
+
+// dataType 成功响应中 data 的类型，响应写作 allOf: [Envelope, {properties: {data: ...}}]
+func (g *generator) dataType(op *Operation) (string, error) {
+	res := g.doc.Response(op, "200")
+	if res == nil {
+		return "", fmt.Errorf("missing 200 response")
+	}
+	media, ok := res.Content["application/json"]
+	if !ok {
+		return "", fmt.Errorf("200 response must be application/json")
+	}
+	for _, sub := range media.Schema.AllOf {
+		if data, ok := sub.Properties["data"]; ok {
+			return g.goType(data)
+		}
+	}
+	return "", nil
+}
+
+func (g *generator) declared(name string) bool {
+	return bytes.Contains(g.buf.Bytes(), []byte("type "+name+" struct"))
+}
+
+func (g *generator) comment(indent string, name string, description string) {
+	if description != "" {
+		g.printf("%s// %s %s\n", indent, name, description)
+	}
+}
+
+// goName 把 camelCase 的名称转换为导出的 Go 标识符，例如 apiVersion 转为 APIVersion
+func goName(name string) string {
+	var words []string
+	runes := []rune(name)
+	start := 0
+	for i := 1; i < len(runes); i++ {
+		upper := unicode.IsUpper(runes[i])
+		if upper && !unicode.IsUpper(runes[i-1]) ||
+			upper && i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	words = append(words, string(runes[start:]))
+	for i, word := range words {
+		if initialisms[strings.ToLower(word)] {
+			words[i] = strings.ToUpper(word)
+			continue
+		}
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, "")
+}
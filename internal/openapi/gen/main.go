@@ -0,0 +1,33 @@
+// gen 按 internal/openapi/openapi.json 生成 client 包中的接口方法
+package main
+
+import (
+	"faucet/internal/openapi"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+func main() {
+	pkg := flag.String("package", "client", "package name of the generated file")
+	output := flag.String("o", "client.gen.go", "output file")
+	flag.Parse()
+
+	if err := generate(*pkg, *output); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func generate(pkg string, output string) error {
+	doc, err := openapi.Load()
+	if err != nil {
+		return err
+	}
+	src, err := doc.GenerateClient(pkg)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(output, src, 0644)
+}
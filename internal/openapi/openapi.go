@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// raw 描述 http api 的 OpenAPI 3 文档，新增或修改路由时需要同步修改
+//
+//go:embed openapi.json
+var raw []byte
+
+const refPrefix = "#/components/"
+
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+type Components struct {
+	Schemas       map[string]*Schema      `json:"schemas"`
+	RequestBodies map[string]*RequestBody `json:"requestBodies"`
+	Responses     map[string]*Response    `json:"responses"`
+}
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Parameters  []*Parameter          `json:"parameters"`
+	RequestBody *RequestBody          `json:"requestBody"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required"`
+	Description string  `json:"description"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Ref      string                `json:"$ref"`
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+type Response struct {
+	Ref         string                `json:"$ref"`
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema 只支持请求校验和客户端生成用到的 JSON Schema 子集
+type Schema struct {
+	Ref              string             `json:"$ref"`
+	Type             string             `json:"type"`
+	Format           string             `json:"format"`
+	Description      string             `json:"description"`
+	Pattern          string             `json:"pattern"`
+	Enum             []string           `json:"enum"`
+	Minimum          *float64           `json:"minimum"`
+	ExclusiveMinimum bool               `json:"exclusiveMinimum"`
+	Required         []string           `json:"required"`
+	Properties       map[string]*Schema `json:"properties"`
+	Items            *Schema            `json:"items"`
+	AllOf            []*Schema          `json:"allOf"`
+	// ErrorCode 校验失败时返回的错误码，为空时沿用外层 schema 的错误码
+	ErrorCode string `json:"x-error-code"`
+
+	pattern *regexp.Regexp
+}
+
+// Route 一个路径和方法对应的接口
+type Route struct {
+	Method    string
+	Path      string
+	Operation *Operation
+}
+
+// Raw 原始的 OpenAPI 文档，通过 /openapi.json 对外提供
+func Raw() []byte {
+	return raw
+}
+
+// Load 解析内置的 OpenAPI 文档，并预编译 schema 中的正则
+func Load() (*Document, error) {
+	doc := &Document{}
+	if err := json.Unmarshal(raw, doc); err != nil {
+		return nil, fmt.Errorf("parse openapi document: %w", err)
+	}
+	var compileErr error
+	doc.walkSchemas(func(s *Schema) {
+		if s.Pattern == "" || compileErr != nil {
+			return
+		}
+		s.pattern, compileErr = regexp.Compile(s.Pattern)
+	})
+	if compileErr != nil {
+		return nil, fmt.Errorf("compile openapi pattern: %w", compileErr)
+	}
+	return doc, nil
+}
+
+// Operation 按方法和 OpenAPI 格式的路径（/faucet/claim/{txHash}）查找接口
+func (d *Document) Operation(method string, path string) (*Operation, bool) {
+	op, ok := d.Paths[path][strings.ToLower(method)]
+	return op, ok
+}
+
+// Routes 按路径和方法排序的全部接口
+func (d *Document) Routes() []*Route {
+	var routes []*Route
+	for path, item := range d.Paths {
+		for method, op := range item {
+			routes = append(routes, &Route{Method: strings.ToUpper(method), Path: path, Operation: op})
+		}
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Schema 展开 $ref，返回实际的 schema 和组件名，非引用时组件名为空
+func (d *Document) Schema(s *Schema) (*Schema, string) {
+	name := ""
+	for s != nil && s.Ref != "" {
+		name = strings.TrimPrefix(s.Ref, refPrefix+"schemas/")
+		s = d.Components.Schemas[name]
+	}
+	return s, name
+}
+
+// RequestBody 展开 $ref，返回实际的请求体和组件名
+func (d *Document) RequestBody(op *Operation) (*RequestBody, string) {
+	body := op.RequestBody
+	if body == nil || body.Ref == "" {
+		return body, ""
+	}
+	name := strings.TrimPrefix(body.Ref, refPrefix+"requestBodies/")
+	return d.Components.RequestBodies[name], name
+}
+
+// Response 展开 $ref，返回 status 对应的响应
+func (d *Document) Response(op *Operation, status string) *Response {
+	res := op.Responses[status]
+	if res != nil && res.Ref != "" {
+		res = d.Components.Responses[strings.TrimPrefix(res.Ref, refPrefix+"responses/")]
+	}
+	return res
+}
+
+func (d *Document) walkSchemas(fn func(s *Schema)) {
+	var walk func(s *Schema)
+	walk = func(s *Schema) {
+		if s == nil {
+			return
+		}
+		fn(s)
+		for _, p := range s.Properties {
+			walk(p)
+		}
+		walk(s.Items)
+		for _, sub := range s.AllOf {
+			walk(sub)
+		}
+	}
+	walkContent := func(content map[string]*MediaType) {
+		for _, media := range content {
+			walk(media.Schema)
+		}
+	}
+	for _, s := range d.Components.Schemas {
+		walk(s)
+	}
+	for _, body := range d.Components.RequestBodies {
+		walkContent(body.Content)
+	}
+	for _, res := range d.Components.Responses {
+		walkContent(res.Content)
+	}
+	for _, item := range d.Paths {
+		for _, op := range item {
+			for _, param := range op.Parameters {
+				walk(param.Schema)
+			}
+			if op.RequestBody != nil {
+				walkContent(op.RequestBody.Content)
+			}
+			for _, res := range op.Responses {
+				walkContent(res.Content)
+			}
+		}
+	}
+}
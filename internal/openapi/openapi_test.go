@@ -0,0 +1,109 @@
+package openapi
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	doc, err := Load()
+	require.Nil(t, err)
+	ids := make(map[string]bool)
+	for _, route := range doc.Routes() {
+		op := route.Operation
+		require.NotEmpty(t, op.OperationID, "%s %s", route.Method, route.Path)
+		require.False(t, ids[op.OperationID], "duplicate operationId %s", op.OperationID)
+		ids[op.OperationID] = true
+		require.NotNil(t, doc.Response(op, "200"), op.OperationID)
+		require.NotNil(t, doc.Response(op, "default"), op.OperationID)
+	}
+	op, ok := doc.Operation("post", "/faucet/nativeToken")
+	require.True(t, ok)
+	require.Equal(t, "requestDrip", op.OperationID)
+}
+
+func TestValidateRequest(t *testing.T) {
+	doc, err := Load()
+	require.Nil(t, err)
+	drip, _ := doc.Operation("POST", "/faucet/nativeToken")
+	amount, _ := doc.Operation("PUT", "/admin/amount")
+	challenge, _ := doc.Operation("GET", "/faucet/challenge")
+	claim, _ := doc.Operation("GET", "/faucet/claim/{txHash}")
+	address := "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+
+	params := func(values map[string]string) ParamFunc {
+		return func(in string, name string) (string, bool) {
+			value, ok := values[in+":"+name]
+			return value, ok
+		}
+	}
+	none := params(nil)
+
+	cases := []struct {
+		op     *Operation
+		params ParamFunc
+		body   string
+		valid  bool
+		field  string
+		code   string
+	}{
+		{drip, none, `{"net":"axm","address":"` + address + `"}`, true, "", ""},
+		{drip, none, `{"net":"AXM","address":"` + address + `","captchaToken":"token"}`, true, "", ""},
+		{drip, none, ``, false, "", ""},
+		{drip, none, `{"net":"axm"`, false, "", ""},
+		{drip, none, `{"net":"axm"}`, false, "address", "INVALID_ADDRESS"},
+		{drip, none, `{"net":"axm","address":"0x123"}`, false, "address", "INVALID_ADDRESS"},
+		{drip, none, `{"net":"eth","address":"` + address + `"}`, false, "net", "UNSUPPORTED_NETWORK"},
+		{drip, none, `{"net":"axm","address":"` + address + `","signature":1}`, false, "signature", ""},
+		{amount, none, `{"net":"axm","amount":0}`, false, "amount", "INVALID_AMOUNT"},
+		{amount, none, `{"net":"axm","amount":"1"}`, false, "amount", "INVALID_AMOUNT"},
+		{amount, none, `{"net":"axm","amount":0.1}`, true, "", ""},
+		{challenge, params(map[string]string{"query:net": "axm", "query:address": address}), ``, true, "", ""},
+		{challenge, params(map[string]string{"query:net": "axm"}), ``, false, "address", "INVALID_ADDRESS"},
+		{claim, params(map[string]string{"path:txHash": "0x1234"}), ``, false, "txHash", ""},
+	}
+	for i, c := range cases {
+		err := doc.ValidateRequest(c.op, c.params, []byte(c.body))
+		if c.valid {
+			require.Nil(t, err, "case %d", i)
+			continue
+		}
+		require.NotNil(t, err, "case %d", i)
+		e, ok := err.(*ValidationError)
+		require.True(t, ok, "case %d", i)
+		require.Equal(t, c.field, e.Field, "case %d: %s", i, err)
+		require.Equal(t, c.code, e.Code, "case %d: %s", i, err)
+	}
+}
+
+func TestValidateValue(t *testing.T) {
+	doc, err := Load()
+	require.Nil(t, err)
+	require.Nil(t, doc.ValidateValue("Address", "address", "0x5FbDB2315678afecb367f032d93F642f64180aa3"))
+	err = doc.ValidateValue("Net", "net", "eth")
+	require.NotNil(t, err)
+	require.Equal(t, "UNSUPPORTED_NETWORK", err.(*ValidationError).Code)
+	require.NotNil(t, doc.ValidateValue("Unknown", "field", ""))
+}
+
+func TestGoName(t *testing.T) {
+	require.Equal(t, "APIVersion", goName("apiVersion"))
+	require.Equal(t, "ListACL", goName("listACL"))
+	require.Equal(t, "ACLEntry", goName("ACLEntry"))
+	require.Equal(t, "IP", goName("ip"))
+	require.Equal(t, "TxHash", goName("txHash"))
+}
+
+// TestGeneratedClient 修改 openapi.json 后需要在 client 目录执行 go generate
+func TestGeneratedClient(t *testing.T) {
+	doc, err := Load()
+	require.Nil(t, err)
+	src, err := doc.GenerateClient("client")
+	require.Nil(t, err)
+	generated, err := ioutil.ReadFile(filepath.Join("..", "..", "client", "client.gen.go"))
+	require.Nil(t, err)
+	require.Equal(t, string(generated), string(src), "client/client.gen.go is out of date, run go generate ./client")
+}
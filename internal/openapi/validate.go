@@ -0,0 +1,203 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValidationError 请求不符合 OpenAPI 文档，Code 为 schema 上 x-error-code 指定的错误码
+type ValidationError struct {
+	Field  string
+	Reason string
+	Code   string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Reason
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+}
+
+// ParamFunc 按参数位置（path、query、header）和名称读取请求参数
+type ParamFunc func(in string, name string) (string, bool)
+
+// ValidateRequest 校验请求的参数和 JSON 请求体
+func (d *Document) ValidateRequest(op *Operation, param ParamFunc, body []byte) error {
+	for _, p := range op.Parameters {
+		value, ok := param(p.In, p.Name)
+		if !ok || value == "" {
+			if p.Required {
+				schema, _ := d.Schema(p.Schema)
+				return &ValidationError{Field: p.Name, Reason: "is required", Code: errorCode(schema, "")}
+			}
+			continue
+		}
+		if err := d.validate(p.Schema, p.Name, paramValue(d, p.Schema, value), ""); err != nil {
+			return err
+		}
+	}
+
+	reqBody, _ := d.RequestBody(op)
+	if reqBody == nil {
+		return nil
+	}
+	if len(strings.TrimSpace(string(body))) == 0 {
+		if reqBody.Required {
+			return &ValidationError{Reason: "request body is required"}
+		}
+		return nil
+	}
+	media, ok := reqBody.Content["application/json"]
+	if !ok {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return &ValidationError{Reason: fmt.Sprintf("invalid json body: %s", err)}
+	}
+	return d.validate(media.Schema, "", value, "")
+}
+
+// ValidateValue 按 components 中名为 schema 的定义校验单个值，供 gRPC 等非 http 入口复用
+func (d *Document) ValidateValue(schema string, field string, value interface{}) error {
+	s, ok := d.Components.Schemas[schema]
+	if !ok {
+		return fmt.Errorf("schema %s not found", schema)
+	}
+	return d.validate(s, field, value, "")
+}
+
+// paramValue 按 schema 类型转换参数，转换失败时保留字符串交给类型检查报错
+func paramValue(d *Document, s *Schema, value string) interface{} {
+	schema, _ := d.Schema(s)
+	if schema == nil {
+		return value
+	}
+	switch schema.Type {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(value, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	}
+	return value
+}
+
+func (d *Document) validate(s *Schema, field string, value interface{}, code string) error {
+	s, _ = d.Schema(s)
+	if s == nil {
+		return nil
+	}
+	code = errorCode(s, code)
+	invalid := func(format string, args ...interface{}) error {
+		return &ValidationError{Field: field, Reason: fmt.Sprintf(format, args...), Code: code}
+	}
+	for _, sub := range s.AllOf {
+		if err := d.validate(sub, field, value, code); err != nil {
+			return err
+		}
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return invalid("must be an object")
+		}
+		for _, name := range s.Required {
+			if v, ok := obj[name]; !ok || v == nil {
+				prop, _ := d.Schema(s.Properties[name])
+				return &ValidationError{Field: join(field, name), Reason: "is required", Code: errorCode(prop, code)}
+			}
+		}
+		for _, name := range sortedKeys(s.Properties) {
+			if v, ok := obj[name]; ok {
+				if err := d.validate(s.Properties[name], join(field, name), v, code); err != nil {
+					return err
+				}
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return invalid("must be an array")
+		}
+		for i, item := range items {
+			if err := d.validate(s.Items, fmt.Sprintf("%s[%d]", field, i), item, code); err != nil {
+				return err
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return invalid("must be a string")
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			return invalid("must be one of %s", strings.Join(s.Enum, ", "))
+		}
+		if s.pattern != nil && !s.pattern.MatchString(str) {
+			return invalid("invalid value %q", str)
+		}
+	case "integer", "number":
+		n, ok := value.(float64)
+		if !ok {
+			return invalid("must be a %s", s.Type)
+		}
+		if s.Type == "integer" && n != math.Trunc(n) {
+			return invalid("must be an integer")
+		}
+		if s.Minimum != nil {
+			if s.ExclusiveMinimum && n <= *s.Minimum {
+				return invalid("must be greater than %v", *s.Minimum)
+			}
+			if n < *s.Minimum {
+				return invalid("must be at least %v", *s.Minimum)
+			}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return invalid("must be a boolean")
+		}
+	}
+	return nil
+}
+
+func errorCode(s *Schema, fallback string) string {
+	if s != nil && s.ErrorCode != "" {
+		return s.ErrorCode
+	}
+	return fallback
+}
+
+func join(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+func sortedKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
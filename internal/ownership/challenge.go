@@ -0,0 +1,134 @@
+package ownership
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	defaultTTL = 5 * time.Minute
+
+	challengeFormat = "Axiom faucet requests proof that you own %s.\n\nNonce: %s\nExpires: %s"
+)
+
+var (
+	ErrNoChallenge       = errors.New("no pending challenge for address, request a new one")
+	ErrChallengeExpired  = errors.New("challenge expired, request a new one")
+	ErrInvalidSignature  = errors.New("invalid signature")
+	ErrSignerNotMatching = errors.New("signer does not match address")
+)
+
+type challenge struct {
+	message  string
+	expireAt time.Time
+}
+
+// Challenger 签发一次性的挑战消息，并校验 personal_sign(EIP-191) 签名以证明地址所有权
+type Challenger struct {
+	ttl        time.Duration
+	lock       sync.Mutex
+	challenges map[string]*challenge
+}
+
+func NewChallenger(ttl time.Duration) *Challenger {
+	if ttl == 0 {
+		ttl = defaultTTL
+	}
+	return &Challenger{
+		ttl:        ttl,
+		challenges: make(map[string]*challenge),
+	}
+}
+
+// Issue 为地址生成挑战消息。未过期的挑战不会被覆盖而是原样返回，
+// 否则任何人都可以反复请求挑战，让地址持有者手中的挑战失效
+func (c *Challenger) Issue(address string) (string, error) {
+	key := strings.ToLower(address)
+	now := time.Now()
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for addr, ch := range c.challenges {
+		if now.After(ch.expireAt) {
+			delete(c.challenges, addr)
+		}
+	}
+	if ch, ok := c.challenges[key]; ok {
+		return ch.message, nil
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	expireAt := now.Add(c.ttl)
+	message := fmt.Sprintf(challengeFormat, common.HexToAddress(address).Hex(), hex.EncodeToString(nonce), expireAt.UTC().Format(time.RFC3339))
+	c.challenges[key] = &challenge{
+		message:  message,
+		expireAt: expireAt,
+	}
+	return message, nil
+}
+
+// Verify 从签名恢复出签名者并与 address 比较。挑战只在签名校验通过后删除，
+// 第三方提交错误的签名不会让挑战失效；同一个挑战只能成功使用一次
+func (c *Challenger) Verify(address string, signature string) error {
+	key := strings.ToLower(address)
+	c.lock.Lock()
+	ch, ok := c.challenges[key]
+	if ok && time.Now().After(ch.expireAt) {
+		delete(c.challenges, key)
+	}
+	c.lock.Unlock()
+
+	if !ok {
+		return ErrNoChallenge
+	}
+	if time.Now().After(ch.expireAt) {
+		return ErrChallengeExpired
+	}
+
+	signer, err := RecoverSigner(ch.message, signature)
+	if err != nil {
+		return err
+	}
+	if signer != common.HexToAddress(address) {
+		return ErrSignerNotMatching
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	// 并发提交同一个签名时只有一个请求能消耗挑战
+	if c.challenges[key] != ch {
+		return ErrNoChallenge
+	}
+	delete(c.challenges, key)
+	return nil
+}
+
+// RecoverSigner 恢复 personal_sign 签名的地址，兼容 v 为 27/28 的钱包签名
+func RecoverSigner(message string, signature string) (common.Address, error) {
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return common.Address{}, ErrInvalidSignature
+	}
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pub, err := crypto.SigToPub(accounts.TextHash([]byte(message)), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("%w: %s", ErrInvalidSignature, err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
@@ -0,0 +1,91 @@
+package ownership
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func personalSign(t *testing.T, message string) (string, string) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	require.Nil(t, err)
+	// 钱包返回的 v 为 27/28
+	sig[crypto.RecoveryIDOffset] += 27
+	return crypto.PubkeyToAddress(key.PublicKey).Hex(), hexutil.Encode(sig)
+}
+
+func TestVerify(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	c := NewChallenger(time.Minute)
+	message, err := c.Issue(address)
+	require.Nil(t, err)
+	sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	require.Nil(t, err)
+
+	require.Nil(t, c.Verify(address, hexutil.Encode(sig)))
+	// 挑战只能使用一次
+	require.ErrorIs(t, c.Verify(address, hexutil.Encode(sig)), ErrNoChallenge)
+}
+
+func TestVerifyWrongSigner(t *testing.T) {
+	c := NewChallenger(time.Minute)
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+	message, err := c.Issue(address)
+	require.Nil(t, err)
+	_, sig := personalSign(t, message)
+	require.ErrorIs(t, c.Verify(address, sig), ErrSignerNotMatching)
+	require.ErrorIs(t, c.Verify(address, "0x1234"), ErrInvalidSignature)
+
+	// 错误的签名不会消耗挑战，持有者仍然可以用原来的挑战完成校验
+	sig2, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+	require.Nil(t, err)
+	require.Nil(t, c.Verify(address, hexutil.Encode(sig2)))
+}
+
+func TestIssueKeepsPendingChallenge(t *testing.T) {
+	c := NewChallenger(time.Minute)
+	signer, _ := personalSign(t, "")
+	first, err := c.Issue(signer)
+	require.Nil(t, err)
+	second, err := c.Issue(signer)
+	require.Nil(t, err)
+	require.Equal(t, first, second, "a pending challenge must not be overwritten")
+
+	c = NewChallenger(time.Millisecond)
+	first, err = c.Issue(signer)
+	require.Nil(t, err)
+	time.Sleep(5 * time.Millisecond)
+	second, err = c.Issue(signer)
+	require.Nil(t, err)
+	require.NotEqual(t, first, second, "an expired challenge is replaced")
+}
+
+func TestVerifyExpired(t *testing.T) {
+	c := NewChallenger(time.Millisecond)
+	signer, _ := personalSign(t, "")
+	message, err := c.Issue(signer)
+	require.Nil(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	_, sig := personalSign(t, message)
+	require.ErrorIs(t, c.Verify(signer, sig), ErrChallengeExpired)
+}
+
+func TestRecoverSigner(t *testing.T) {
+	address, sig := personalSign(t, "hello")
+	signer, err := RecoverSigner("hello", sig)
+	require.Nil(t, err)
+	require.Equal(t, address, signer.Hex())
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"faucet/internal/repo"
+	"sync"
+	"time"
+)
+
+// sweepInterval 清理已经回满令牌的 key，避免按 ip/地址限流时 map 无限增长
+const sweepInterval = time.Minute
+
+// Result 单次限流判断结果，用于填充 X-RateLimit-* 和 Retry-After 响应头
+type Result struct {
+	Allowed bool
+	// Limit 令牌桶容量
+	Limit int
+	// Remaining 本次请求之后剩余可用的令牌数
+	Remaining int
+	// RetryAfter 被拒绝时，距离下一次允许请求的时间
+	RetryAfter time.Duration
+	// ResetAfter 距离令牌桶回满的时间
+	ResetAfter time.Duration
+}
+
+// Limiter 基于 GCRA（generic cell rate algorithm）的令牌桶限流器，按 key 分别计数。
+// 每个 key 只记录理论到达时间（TAT），不存在按秒清零导致的边界突发。
+type Limiter struct {
+	emission  time.Duration
+	tolerance time.Duration
+	burst     int
+	now       func() time.Time
+
+	lock      sync.Mutex
+	tat       map[string]time.Time
+	lastSweep time.Time
+}
+
+// New 每 period 补充 limit 个令牌，最多累积 burst 个
+func New(limit int, period time.Duration, burst int) *Limiter {
+	if burst <= 0 {
+		burst = limit
+	}
+	emission := period / time.Duration(limit)
+	// limit 超过 period 的纳秒数时整除为 0，Remaining 会除零
+	if emission < time.Nanosecond {
+		emission = time.Nanosecond
+	}
+	return &Limiter{
+		emission:  emission,
+		tolerance: emission * time.Duration(burst),
+		burst:     burst,
+		now:       time.Now,
+		tat:       make(map[string]time.Time),
+	}
+}
+
+// FromConfig 按配置构建限流器，limit 未配置时返回 nil 表示不限流
+func FromConfig(rate repo.Rate) *Limiter {
+	if rate.Limit <= 0 {
+		return nil
+	}
+	period := rate.Period
+	if period <= 0 {
+		period = time.Second
+	}
+	return New(rate.Limit, period, rate.Burst)
+}
+
+// Allow 为 key 消耗一个令牌
+func (l *Limiter) Allow(key string) Result {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	tat, ok := l.tat[key]
+	if !ok || tat.Before(now) {
+		tat = now
+	}
+	newTat := tat.Add(l.emission)
+	allowAt := newTat.Add(-l.tolerance)
+
+	res := Result{Limit: l.burst}
+	if now.Before(allowAt) {
+		res.RetryAfter = allowAt.Sub(now)
+		res.ResetAfter = tat.Sub(now)
+		return res
+	}
+
+	l.tat[key] = newTat
+	res.Allowed = true
+	res.Remaining = int((l.tolerance - newTat.Sub(now)) / l.emission)
+	res.ResetAfter = newTat.Sub(now)
+	return res
+}
+
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, tat := range l.tat {
+		if tat.Before(now) {
+			delete(l.tat, key)
+		}
+	}
+}
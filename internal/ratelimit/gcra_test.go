@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (f *fakeClock) now() time.Time { return f.t }
+
+func TestAllowBurst(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	l := New(10, time.Second, 5)
+	l.now = clock.now
+
+	for i := 0; i < 5; i++ {
+		res := l.Allow("a")
+		require.True(t, res.Allowed)
+		require.Equal(t, 4-i, res.Remaining)
+	}
+	res := l.Allow("a")
+	require.False(t, res.Allowed)
+	require.Equal(t, 100*time.Millisecond, res.RetryAfter)
+
+	// 其他 key 不受影响
+	require.True(t, l.Allow("b").Allowed)
+
+	clock.t = clock.t.Add(100 * time.Millisecond)
+	require.True(t, l.Allow("a").Allowed)
+	require.False(t, l.Allow("a").Allowed)
+}
+
+func TestNoBoundaryBurst(t *testing.T) {
+	// 旧的按秒计数器在秒边界前后可以通过 2 倍请求
+	clock := &fakeClock{t: time.Unix(1000, 0).Add(999 * time.Millisecond)}
+	l := New(3, time.Second, 3)
+	l.now = clock.now
+
+	allowed := 0
+	for i := 0; i < 3; i++ {
+		if l.Allow("a").Allowed {
+			allowed++
+		}
+	}
+	clock.t = clock.t.Add(2 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		if l.Allow("a").Allowed {
+			allowed++
+		}
+	}
+	require.Equal(t, 3, allowed)
+}
+
+func TestSweep(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	l := New(1, time.Second, 1)
+	l.now = clock.now
+
+	l.Allow("a")
+	require.Len(t, l.tat, 1)
+	clock.t = clock.t.Add(2 * sweepInterval)
+	l.Allow("b")
+	require.Len(t, l.tat, 1)
+}
+
+func TestTinyEmission(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(1000, 0)}
+	l := New(1000, time.Nanosecond, 2)
+	l.now = clock.now
+
+	res := l.Allow("a")
+	require.True(t, res.Allowed)
+	require.Equal(t, 1, res.Remaining)
+	require.True(t, l.Allow("a").Allowed)
+	require.False(t, l.Allow("a").Allowed)
+}
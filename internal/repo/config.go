@@ -3,6 +3,7 @@ package repo
 import (
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -12,10 +13,18 @@ const (
 )
 
 type Config struct {
-	RepoRoot string
-	Axiom    AXIOM   `toml:"axiom" json:"axiom"`
-	Network  Network `toml:"network" json:"network"`
-	Log      Log     `toml:"log" json:"log"`
+	RepoRoot  string
+	Axiom     AXIOM     `toml:"axiom" json:"axiom"`
+	Network   Network   `toml:"network" json:"network"`
+	RateLimit RateLimit `mapstructure:"rate_limit" toml:"rate_limit" json:"rate_limit"`
+	Admin     Admin     `toml:"admin" json:"admin"`
+	Retention Retention `toml:"retention" json:"retention"`
+	UI        UI        `toml:"ui" json:"ui"`
+	GRPC      GRPC      `toml:"grpc" json:"grpc"`
+	Metrics   Metrics   `toml:"metrics" json:"metrics"`
+	Notifier  Notifier  `toml:"notifier" json:"notifier"`
+	Shutdown  Shutdown  `toml:"shutdown" json:"shutdown"`
+	Log       Log       `toml:"log" json:"log"`
 }
 
 // Log are config about log
@@ -31,17 +40,269 @@ type LogModule struct {
 }
 
 type AXIOM struct {
-	AxiomAddr    string `mapstructure:"axiom_addr" json:"axiom_addr"`
-	AxiomKeyPath string `mapstructure:"axiom_key_path" json:"axiom_key_path"`
-	MinConfirm   uint64 `mapstructure:"min_confirm" json:"min_confirm"`
+	AxiomAddr    string  `mapstructure:"axiom_addr" json:"axiom_addr"`
+	AxiomKeyPath string  `mapstructure:"axiom_key_path" json:"axiom_key_path"`
+	MinConfirm   uint64  `mapstructure:"min_confirm" json:"min_confirm"`
+	Amount       float64 `mapstructure:"amount" json:"amount"`
+	// Cooldown is the network-wide claim limit per address, TokenCooldowns and tier cooldowns override it
+	Cooldown       Cooldown            `mapstructure:"cooldown" json:"cooldown"`
+	TokenCooldowns map[string]Cooldown `mapstructure:"token_cooldowns" json:"token_cooldowns"`
+	// IPDailyLimit caps how many claims one client ip can make per UTC day, 0 means unlimited
+	IPDailyLimit  int           `mapstructure:"ip_daily_limit" json:"ip_daily_limit"`
+	Captcha       Captcha       `mapstructure:"captcha" json:"captcha"`
+	Ownership     Ownership     `mapstructure:"ownership" json:"ownership"`
+	Tiers         []Tier        `mapstructure:"tiers" json:"tiers"`
+	Budget        Budget        `mapstructure:"budget" json:"budget"`
+	DynamicAmount DynamicAmount `mapstructure:"dynamic_amount" json:"dynamic_amount"`
+}
+
+// DynamicAmount scales the drip amount down as the faucet balance, minus the
+// amount expected to be burned within Runway at the recent rate, gets lower
+type DynamicAmount struct {
+	Enable bool   `mapstructure:"enable" json:"enable"`
+	Curve  string `mapstructure:"curve" json:"curve"`
+	// LowBalance, HighBalance and MinAmount define the linear curve
+	LowBalance  float64 `mapstructure:"low_balance" json:"low_balance"`
+	HighBalance float64 `mapstructure:"high_balance" json:"high_balance"`
+	MinAmount   float64 `mapstructure:"min_amount" json:"min_amount"`
+	// Steps define the step curve
+	Steps      []AmountStep  `mapstructure:"steps" json:"steps"`
+	BurnWindow time.Duration `mapstructure:"burn_window" json:"burn_window"`
+	Runway     time.Duration `mapstructure:"runway" json:"runway"`
+}
+
+// AmountStep hands out Amount while the effective balance is at least Balance
+type AmountStep struct {
+	Balance float64 `mapstructure:"balance" json:"balance"`
+	Amount  float64 `mapstructure:"amount" json:"amount"`
+}
+
+// Budget caps the total amount dispensed in the last rolling hour and day, 0 means unlimited
+type Budget struct {
+	Hourly float64 `mapstructure:"hourly" json:"hourly"`
+	Daily  float64 `mapstructure:"daily" json:"daily"`
+}
+
+// Tier are quotas granted to api key holders
+type Tier struct {
+	Name   string  `mapstructure:"name" json:"name"`
+	Amount float64 `mapstructure:"amount" json:"amount"`
+	// Cooldown overrides the token and network cooldown for api key holders of this tier
+	Cooldown Cooldown `mapstructure:"cooldown" json:"cooldown"`
+	// DailyBudget caps the total amount one api key can claim per UTC day, 0 means unlimited
+	DailyBudget float64 `mapstructure:"daily_budget" json:"daily_budget"`
+}
+
+// Cooldown limits how many times one address can claim within a window, zero
+// fields fall back to the next level: tier, then token, then network
+type Cooldown struct {
+	Period    time.Duration `mapstructure:"period" json:"period"`
+	MaxClaims int           `mapstructure:"max_claims" json:"max_claims"`
+	// Mode is sliding (the last Period) or calendar (the current UTC day, Period is ignored)
+	Mode string `mapstructure:"mode" json:"mode"`
+}
+
+// Merge fills zero fields of c from fallback
+func (c Cooldown) Merge(fallback Cooldown) Cooldown {
+	if c.Period == 0 {
+		c.Period = fallback.Period
+	}
+	if c.MaxClaims == 0 {
+		c.MaxClaims = fallback.MaxClaims
+	}
+	if c.Mode == "" {
+		c.Mode = fallback.Mode
+	}
+	return c
+}
+
+// CooldownFor resolves the cooldown of token claims on this network, tier may be nil
+func (a *AXIOM) CooldownFor(token string, tier *Tier) Cooldown {
+	cooldown := a.Cooldown
+	if c, ok := a.TokenCooldowns[token]; ok {
+		cooldown = c.Merge(cooldown)
+	}
+	if tier != nil {
+		cooldown = tier.Cooldown.Merge(cooldown)
+	}
+	return cooldown
+}
+
+// Tier finds the tier by name
+func (a *AXIOM) Tier(name string) (*Tier, bool) {
+	for i := range a.Tiers {
+		if a.Tiers[i].Name == name {
+			return &a.Tiers[i], true
+		}
+	}
+	return nil, false
+}
+
+// Captcha are config about human verification in front of the faucet
+type Captcha struct {
+	Enable   bool   `mapstructure:"enable" json:"enable"`
+	Provider string `mapstructure:"provider" json:"provider"`
+	Secret   string `mapstructure:"secret" json:"secret"`
+	// SiteKey is the public key the web ui renders the captcha widget with
+	SiteKey  string        `mapstructure:"site_key" json:"site_key"`
+	Endpoint string        `mapstructure:"endpoint" json:"endpoint"`
+	MinScore float64       `mapstructure:"min_score" json:"min_score"`
+	Timeout  time.Duration `mapstructure:"timeout" json:"timeout"`
+}
+
+// Ownership requires the requester to sign a server-issued challenge with the receiving address
+type Ownership struct {
+	Enable       bool          `mapstructure:"enable" json:"enable"`
+	ChallengeTTL time.Duration `mapstructure:"challenge_ttl" json:"challenge_ttl"`
 }
 
 type Network struct {
 	Port string `mapstructure:"port" json:"port"`
+	// TrustedProxies are ips or CIDRs whose X-Forwarded-For, Forwarded and PROXY protocol headers are believed
+	TrustedProxies  []string `mapstructure:"trusted_proxies" json:"trusted_proxies"`
+	ForwardedHeader bool     `mapstructure:"forwarded_header" json:"forwarded_header"`
+	ProxyProtocol   bool     `mapstructure:"proxy_protocol" json:"proxy_protocol"`
+	TLS             TLS      `mapstructure:"tls" json:"tls"`
+}
+
+// TLS serves the public port over https, Cert and Key are reloaded when the files change
+type TLS struct {
+	Enable bool   `mapstructure:"enable" json:"enable"`
+	Cert   string `mapstructure:"cert" json:"cert"`
+	Key    string `mapstructure:"key" json:"key"`
+	// RedirectPort listens for plain http and redirects every request to https
+	RedirectPort string `mapstructure:"redirect_port" json:"redirect_port"`
+}
+
+// Admin are config about the authenticated /admin api
+type Admin struct {
+	Enable bool `mapstructure:"enable" json:"enable"`
+	// Tokens are accepted as "Authorization: Bearer <token>"
+	Tokens []string `mapstructure:"tokens" json:"-"`
+	// Port serves the admin api on its own listener, leaving it empty requires AllowPublicPort
+	Port string `mapstructure:"port" json:"port"`
+	// AllowPublicPort explicitly opts in to mounting /admin on the public port when Port is empty
+	AllowPublicPort bool `mapstructure:"allow_public_port" json:"allow_public_port"`
+	// TLSCert and TLSKey enable https on the admin listener
+	TLSCert string `mapstructure:"tls_cert" json:"tls_cert"`
+	TLSKey  string `mapstructure:"tls_key" json:"tls_key"`
+	// ClientCA is the only ca trusted for admin client certificates, which then authenticate
+	// without a token. It is required on the admin listener, and only requested when
+	// /admin is mounted on the public https port
+	ClientCA string `mapstructure:"client_ca" json:"client_ca"`
+	// RequireClientCert rejects tokens, only verified client certificates are accepted
+	RequireClientCert bool `mapstructure:"require_client_cert" json:"require_client_cert"`
+}
+
+// UI serves the embedded web page at /
+type UI struct {
+	Enable bool `mapstructure:"enable" json:"enable"`
+}
+
+// GRPC serves the faucet grpc api on its own port, over tls when network.tls is enabled
+type GRPC struct {
+	Enable bool   `mapstructure:"enable" json:"enable"`
+	Port   string `mapstructure:"port" json:"port"`
+}
+
+// Metrics serves prometheus text metrics at /metrics on its own port
+type Metrics struct {
+	Enable bool   `mapstructure:"enable" json:"enable"`
+	Port   string `mapstructure:"port" json:"port"`
+}
+
+// Notifier posts {"text": ...} to WebhookURL when the faucet balance drops below
+// LowBalance or a claim transaction fails on chain
+type Notifier struct {
+	Enable     bool   `mapstructure:"enable" json:"enable"`
+	WebhookURL string `mapstructure:"webhook_url" json:"-"`
+	// LowBalance in ether, 0 disables the balance alert
+	LowBalance float64 `mapstructure:"low_balance" json:"low_balance"`
+	// Interval between two balance checks
+	Interval time.Duration `mapstructure:"interval" json:"interval"`
+}
+
+// Shutdown bounds how long in-flight drips are waited for after a stop signal,
+// new requests get 503 meanwhile and unconfirmed txs stay pending in the store
+type Shutdown struct {
+	Timeout time.Duration `mapstructure:"timeout" json:"timeout"`
+}
+
+// Retention controls how long cooldown records are kept before the janitor removes them
+type Retention struct {
+	Enable   bool          `mapstructure:"enable" json:"enable"`
+	Interval time.Duration `mapstructure:"interval" json:"interval"`
+	// Claims is never shorter than the longest configured cooldown
+	Claims   time.Duration `mapstructure:"claims" json:"claims"`
+	IPQuotas time.Duration `mapstructure:"ip_quotas" json:"ip_quotas"`
+	// Archive appends expired claims to ArchiveDir/claims-<date>.jsonl before deleting them
+	Archive    bool   `mapstructure:"archive" json:"archive"`
+	ArchiveDir string `mapstructure:"archive_dir" json:"archive_dir"`
+}
+
+// RateLimit are token-bucket limits keyed by the whole server, client ip, receiving address and api key
+type RateLimit struct {
+	Global  Rate `mapstructure:"global" json:"global"`
+	IP      Rate `mapstructure:"ip" json:"ip"`
+	Address Rate `mapstructure:"address" json:"address"`
+	APIKey  Rate `mapstructure:"api_key" json:"api_key"`
+	// IPv4Prefix and IPv6Prefix bucket client ips into subnets before counting
+	IPv4Prefix int `mapstructure:"ipv4_prefix" json:"ipv4_prefix"`
+	IPv6Prefix int `mapstructure:"ipv6_prefix" json:"ipv6_prefix"`
 }
 
+// Rate allows Limit requests per Period with bursts up to Burst, Limit 0 disables the limit
+type Rate struct {
+	Limit  int           `mapstructure:"limit" json:"limit"`
+	Period time.Duration `mapstructure:"period" json:"period"`
+	Burst  int           `mapstructure:"burst" json:"burst"`
+}
+
+// defaultConfig 是 faucet.toml 中未配置字段的取值，与 config/faucet.toml 模板保持一致
 func defaultConfig() *Config {
-	return &Config{}
+	return &Config{
+		Axiom: AXIOM{
+			AxiomKeyPath: "axiom.key",
+			MinConfirm:   1,
+			Amount:       0.5,
+			Cooldown:     Cooldown{Period: 24 * time.Hour, MaxClaims: 1, Mode: "sliding"},
+			Captcha:      Captcha{Provider: "hcaptcha", MinScore: 0.5, Timeout: 5 * time.Second},
+			Ownership:    Ownership{ChallengeTTL: 5 * time.Minute},
+			DynamicAmount: DynamicAmount{
+				Curve:      "linear",
+				BurnWindow: time.Hour,
+				Runway:     24 * time.Hour,
+			},
+		},
+		Network: Network{
+			Port: "8080",
+			TLS:  TLS{Cert: "tls/server.crt", Key: "tls/server.key"},
+		},
+		RateLimit: RateLimit{
+			Global:     Rate{Limit: 200, Period: time.Second, Burst: 200},
+			IPv4Prefix: 32,
+			IPv6Prefix: 64,
+		},
+		Admin:    Admin{Port: "8081"},
+		UI:       UI{Enable: true},
+		GRPC:     GRPC{Port: "9090"},
+		Metrics:  Metrics{Port: "9100"},
+		Notifier: Notifier{Interval: time.Minute},
+		Shutdown: Shutdown{Timeout: 30 * time.Second},
+		Retention: Retention{
+			Enable:     true,
+			Interval:   time.Hour,
+			Claims:     7 * 24 * time.Hour,
+			IPQuotas:   48 * time.Hour,
+			ArchiveDir: "archive",
+		},
+		Log: Log{
+			Dir:      "logs",
+			Filename: "faucet.log",
+			Level:    "info",
+			Module:   LogModule{ApiServer: "info"},
+		},
+	}
 }
 
 func UnmarshalConfig(configRoot string) (*Config, error) {
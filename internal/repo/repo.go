@@ -32,6 +32,12 @@ const (
 
 	// API name
 	APIName = "api"
+
+	// StoreName is the leveldb dir name
+	StoreName = "store"
+
+	// BackupName is the dir name of online store backups
+	BackupName = "backups"
 )
 
 var RootPath string
@@ -0,0 +1,312 @@
+package repo
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// FieldError 一个不合法的配置项，Field 为 faucet.toml 中的路径
+type FieldError struct {
+	Field  string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+}
+
+// ValidationError 汇总全部不合法的配置项，而不是遇到第一个就返回
+type ValidationError struct {
+	Errors []*FieldError
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Errors)+1)
+	lines = append(lines, fmt.Sprintf("invalid %s, %d error(s):", configName, len(e.Errors)))
+	for _, err := range e.Errors {
+		lines = append(lines, "  - "+err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// validator 收集错误，RepoRoot 为空时跳过文件是否存在的检查
+type validator struct {
+	root   string
+	errors []*FieldError
+}
+
+func (v *validator) add(field string, format string, args ...interface{}) {
+	v.errors = append(v.errors, &FieldError{Field: field, Reason: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) check(ok bool, field string, format string, args ...interface{}) {
+	if !ok {
+		v.add(field, format, args...)
+	}
+}
+
+func (v *validator) port(field string, port string) {
+	n, err := strconv.Atoi(port)
+	v.check(err == nil && n > 0 && n <= 65535, field, "must be a port number between 1 and 65535, got %q", port)
+}
+
+func (v *validator) positive(field string, d time.Duration) {
+	v.check(d > 0, field, "must be a positive duration, got %s", d)
+}
+
+func (v *validator) url(field string, raw string, schemes ...string) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		v.add(field, "must be a %s url, got %q", strings.Join(schemes, "/"), raw)
+		return
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme {
+			return
+		}
+	}
+	v.add(field, "must be a %s url, got %q", strings.Join(schemes, "/"), raw)
+}
+
+// file 检查 repo 目录下的文件，required 为 false 时允许留空
+func (v *validator) file(field string, path string, required bool) {
+	if path == "" {
+		v.check(!required, field, "must not be empty")
+		return
+	}
+	if v.root == "" {
+		return
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(v.root, path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		v.add(field, "%s", err)
+	}
+}
+
+func (v *validator) level(field string, level string) {
+	_, err := logrus.ParseLevel(level)
+	v.check(err == nil, field, "must be one of trace, debug, info, warn, error, fatal, panic, got %q", level)
+}
+
+func (v *validator) cooldown(field string, c Cooldown) {
+	switch c.Mode {
+	case "sliding":
+		v.positive(field+".period", c.Period)
+	case "calendar":
+	default:
+		v.add(field+".mode", "must be sliding or calendar, got %q", c.Mode)
+	}
+	v.check(c.MaxClaims > 0, field+".max_claims", "must be positive, got %d", c.MaxClaims)
+}
+
+func (v *validator) rate(field string, r Rate) {
+	v.check(r.Limit >= 0, field+".limit", "must not be negative, got %d", r.Limit)
+	v.check(r.Burst >= 0, field+".burst", "must not be negative, got %d", r.Burst)
+	if r.Limit > 0 {
+		v.positive(field+".period", r.Period)
+	}
+}
+
+// Validate 检查配置的取值范围、取值之间的关系和引用的文件，一次返回全部错误
+func (c *Config) Validate() error {
+	v := &validator{root: c.RepoRoot}
+	c.validateAxiom(v)
+	c.validateNetwork(v)
+	c.validateAdmin(v)
+
+	v.check(c.RateLimit.IPv4Prefix >= 0 && c.RateLimit.IPv4Prefix <= 32, "rate_limit.ipv4_prefix", "must be between 0 and 32, got %d", c.RateLimit.IPv4Prefix)
+	v.check(c.RateLimit.IPv6Prefix >= 0 && c.RateLimit.IPv6Prefix <= 128, "rate_limit.ipv6_prefix", "must be between 0 and 128, got %d", c.RateLimit.IPv6Prefix)
+	v.rate("rate_limit.global", c.RateLimit.Global)
+	v.rate("rate_limit.ip", c.RateLimit.IP)
+	v.rate("rate_limit.address", c.RateLimit.Address)
+	v.rate("rate_limit.api_key", c.RateLimit.APIKey)
+
+	if c.GRPC.Enable {
+		v.port("grpc.port", c.GRPC.Port)
+	}
+	if c.Metrics.Enable {
+		v.port("metrics.port", c.Metrics.Port)
+	}
+	c.validateListeners(v)
+	if c.Notifier.Enable {
+		v.url("notifier.webhook_url", c.Notifier.WebhookURL, "http", "https")
+		v.check(c.Notifier.LowBalance >= 0, "notifier.low_balance", "must not be negative, got %v", c.Notifier.LowBalance)
+		v.positive("notifier.interval", c.Notifier.Interval)
+	}
+	if c.Retention.Enable {
+		v.positive("retention.interval", c.Retention.Interval)
+		v.check(c.Retention.Claims >= 0, "retention.claims", "must not be negative, got %s", c.Retention.Claims)
+		v.check(c.Retention.IPQuotas >= 0, "retention.ip_quotas", "must not be negative, got %s", c.Retention.IPQuotas)
+		v.check(!c.Retention.Archive || c.Retention.ArchiveDir != "", "retention.archive_dir", "must not be empty when archive is enabled")
+	}
+	v.positive("shutdown.timeout", c.Shutdown.Timeout)
+
+	v.check(c.Log.Dir != "", "log.dir", "must not be empty")
+	v.check(c.Log.Filename != "", "log.filename", "must not be empty")
+	v.level("log.level", c.Log.Level)
+	v.level("log.module.api_server", c.Log.Module.ApiServer)
+
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errors}
+}
+
+func (c *Config) validateAxiom(v *validator) {
+	a := &c.Axiom
+	v.url("axiom.axiom_addr", a.AxiomAddr, "http", "https", "ws", "wss")
+	v.file("axiom.axiom_key_path", a.AxiomKeyPath, true)
+	v.check(a.Amount > 0, "axiom.amount", "must be positive, got %v", a.Amount)
+	v.check(a.IPDailyLimit >= 0, "axiom.ip_daily_limit", "must not be negative, got %d", a.IPDailyLimit)
+	v.check(a.Budget.Hourly >= 0, "axiom.budget.hourly", "must not be negative, got %v", a.Budget.Hourly)
+	v.check(a.Budget.Daily >= 0, "axiom.budget.daily", "must not be negative, got %v", a.Budget.Daily)
+
+	// 冷却按合并后的结果检查，与发放时使用的配置一致
+	var tokens []string
+	for token := range a.TokenCooldowns {
+		if token != "native" {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Strings(tokens)
+	tokens = append([]string{"native"}, tokens...)
+	for _, token := range tokens {
+		field := "axiom.cooldown"
+		if _, ok := a.TokenCooldowns[token]; ok {
+			field = "axiom.token_cooldowns." + token
+		}
+		v.cooldown(field, a.CooldownFor(token, nil))
+	}
+	names := make(map[string]bool)
+	for i := range a.Tiers {
+		tier := &a.Tiers[i]
+		field := fmt.Sprintf("axiom.tiers[%d]", i)
+		v.check(tier.Name != "", field+".name", "must not be empty")
+		v.check(!names[tier.Name], field+".name", "duplicate tier %q", tier.Name)
+		names[tier.Name] = true
+		v.check(tier.Amount >= 0, field+".amount", "must not be negative, got %v", tier.Amount)
+		v.check(tier.DailyBudget >= 0, field+".daily_budget", "must not be negative, got %v", tier.DailyBudget)
+		// 每个 tier 与每种 token 的冷却都要合并后检查
+		for _, token := range tokens {
+			tokenField := field + ".cooldown"
+			if token != "native" {
+				tokenField = field + ".token_cooldowns." + token
+			}
+			v.cooldown(tokenField, a.CooldownFor(token, tier))
+		}
+	}
+
+	if a.Captcha.Enable {
+		switch strings.ToLower(a.Captcha.Provider) {
+		case "hcaptcha", "recaptcha", "turnstile":
+		default:
+			v.add("axiom.captcha.provider", "must be hcaptcha, recaptcha or turnstile, got %q", a.Captcha.Provider)
+		}
+		v.check(a.Captcha.Secret != "", "axiom.captcha.secret", "must not be empty when captcha is enabled")
+		if a.Captcha.Endpoint != "" {
+			v.url("axiom.captcha.endpoint", a.Captcha.Endpoint, "http", "https")
+		}
+		v.check(a.Captcha.MinScore >= 0 && a.Captcha.MinScore <= 1, "axiom.captcha.min_score", "must be between 0 and 1, got %v", a.Captcha.MinScore)
+		v.check(a.Captcha.Timeout >= 0, "axiom.captcha.timeout", "must not be negative, got %s", a.Captcha.Timeout)
+	}
+	if a.Ownership.Enable {
+		v.positive("axiom.ownership.challenge_ttl", a.Ownership.ChallengeTTL)
+	}
+	if d := a.DynamicAmount; d.Enable {
+		switch strings.ToLower(d.Curve) {
+		case "linear":
+			v.check(d.HighBalance > d.LowBalance, "axiom.dynamic_amount.high_balance", "must be greater than low_balance %v, got %v", d.LowBalance, d.HighBalance)
+			v.check(d.MinAmount >= 0, "axiom.dynamic_amount.min_amount", "must not be negative, got %v", d.MinAmount)
+		case "step":
+			v.check(len(d.Steps) > 0, "axiom.dynamic_amount.steps", "step curve requires at least one step")
+			for i, step := range d.Steps {
+				v.check(step.Amount > 0, fmt.Sprintf("axiom.dynamic_amount.steps[%d].amount", i), "must be positive, got %v", step.Amount)
+			}
+		default:
+			v.add("axiom.dynamic_amount.curve", "must be linear or step, got %q", d.Curve)
+		}
+		v.check(d.BurnWindow >= 0, "axiom.dynamic_amount.burn_window", "must not be negative, got %s", d.BurnWindow)
+		v.check(d.Runway >= 0, "axiom.dynamic_amount.runway", "must not be negative, got %s", d.Runway)
+	}
+}
+
+func (c *Config) validateNetwork(v *validator) {
+	n := &c.Network
+	v.port("network.port", n.Port)
+	for i, proxy := range n.TrustedProxies {
+		_, _, err := net.ParseCIDR(proxy)
+		v.check(err == nil || net.ParseIP(proxy) != nil, fmt.Sprintf("network.trusted_proxies[%d]", i), "must be an ip or CIDR, got %q", proxy)
+	}
+	if n.TLS.Enable {
+		v.file("network.tls.cert", n.TLS.Cert, true)
+		v.file("network.tls.key", n.TLS.Key, true)
+		if n.TLS.RedirectPort != "" {
+			v.port("network.tls.redirect_port", n.TLS.RedirectPort)
+		}
+	}
+}
+
+// validateListeners 启用的监听端口两两不能相同，否则要到启动监听时才会失败
+func (c *Config) validateListeners(v *validator) {
+	type listener struct {
+		field string
+		port  string
+	}
+	listeners := []listener{{"network.port", c.Network.Port}}
+	if c.Network.TLS.Enable && c.Network.TLS.RedirectPort != "" {
+		listeners = append(listeners, listener{"network.tls.redirect_port", c.Network.TLS.RedirectPort})
+	}
+	if c.Admin.Enable && c.Admin.Port != "" {
+		listeners = append(listeners, listener{"admin.port", c.Admin.Port})
+	}
+	if c.GRPC.Enable {
+		listeners = append(listeners, listener{"grpc.port", c.GRPC.Port})
+	}
+	if c.Metrics.Enable {
+		listeners = append(listeners, listener{"metrics.port", c.Metrics.Port})
+	}
+	for i, l := range listeners {
+		for _, prev := range listeners[:i] {
+			v.check(l.port != prev.port, l.field, "must differ from %s %s", prev.field, prev.port)
+		}
+	}
+}
+
+func (c *Config) validateAdmin(v *validator) {
+	a := &c.Admin
+	if !a.Enable {
+		return
+	}
+	v.check(a.Port != "" || a.AllowPublicPort, "admin.port", "must not be empty unless allow_public_port is set")
+	if a.Port != "" {
+		v.port("admin.port", a.Port)
+		v.check((a.TLSCert == "") == (a.TLSKey == ""), "admin.tls_key", "tls_cert and tls_key must be set together")
+		if a.TLSCert != "" {
+			v.file("admin.tls_cert", a.TLSCert, true)
+			v.file("admin.tls_key", a.TLSKey, true)
+		}
+		v.check(a.ClientCA == "" || a.TLSCert != "", "admin.client_ca", "requires tls_cert and tls_key")
+	} else {
+		v.check(a.ClientCA == "" || c.Network.TLS.Enable, "admin.client_ca", "requires network.tls.enable when /admin is on the public port")
+	}
+	v.file("admin.client_ca", a.ClientCA, false)
+	for i, token := range a.Tokens {
+		v.check(token != "", fmt.Sprintf("admin.tokens[%d]", i), "must not be empty")
+	}
+	if a.RequireClientCert {
+		v.check(a.ClientCA != "", "admin.require_client_cert", "requires admin.client_ca")
+	} else {
+		v.check(len(a.Tokens) > 0 || a.ClientCA != "", "admin.tokens", "must not be empty unless client certificates are verified")
+	}
+}
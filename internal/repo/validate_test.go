@@ -0,0 +1,110 @@
+package repo
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTemplate(t *testing.T) {
+	root := t.TempDir()
+	template, err := ioutil.ReadFile(filepath.Join(ConfigPath, ConfigName))
+	require.Nil(t, err)
+	require.Nil(t, ioutil.WriteFile(filepath.Join(root, ConfigName), template, 0644))
+
+	config, err := UnmarshalConfig(root)
+	require.Nil(t, err)
+	err = config.Validate()
+	require.NotNil(t, err, "the key file is missing")
+	require.Equal(t, []string{"axiom.axiom_key_path"}, fields(err))
+
+	require.Nil(t, ioutil.WriteFile(filepath.Join(root, "axiom.key"), []byte("00"), 0600))
+	require.Nil(t, config.Validate())
+}
+
+func TestValidateListsEveryField(t *testing.T) {
+	config := defaultConfig()
+	config.Axiom.AxiomAddr = "localhost:8881"
+	config.Axiom.Amount = 0
+	config.Axiom.Cooldown.Mode = "weekly"
+	config.Axiom.Tiers = []Tier{{Name: "ci"}, {Name: "ci", Cooldown: Cooldown{MaxClaims: -1}}}
+	config.Network.Port = ""
+	config.Network.TrustedProxies = []string{"10.0.0.0/8", "proxy.local"}
+	config.Admin = Admin{Enable: true, Port: "admin", RequireClientCert: true}
+	config.GRPC = GRPC{Enable: true, Port: "70000"}
+	config.Metrics = Metrics{Enable: true, Port: "70000"}
+	config.Notifier = Notifier{Enable: true, WebhookURL: "hooks.local"}
+	config.RateLimit.IP = Rate{Limit: 10}
+	config.Shutdown.Timeout = 0
+	config.Log.Level = "verbose"
+	config.Axiom.Captcha = Captcha{Enable: true, Provider: "geetest", Timeout: time.Second}
+
+	err := config.Validate()
+	require.NotNil(t, err)
+	require.Equal(t, []string{
+		"axiom.axiom_addr",
+		"axiom.amount",
+		"axiom.cooldown.mode",
+		"axiom.tiers[0].cooldown.mode",
+		"axiom.tiers[1].name",
+		"axiom.tiers[1].cooldown.mode",
+		"axiom.tiers[1].cooldown.max_claims",
+		"axiom.captcha.provider",
+		"axiom.captcha.secret",
+		"network.port",
+		"network.trusted_proxies[1]",
+		"admin.port",
+		"admin.require_client_cert",
+		"rate_limit.ip.period",
+		"grpc.port",
+		"metrics.port",
+		"metrics.port",
+		"notifier.webhook_url",
+		"notifier.interval",
+		"shutdown.timeout",
+		"log.level",
+	}, fields(err))
+	require.Contains(t, err.Error(), `axiom.axiom_addr: must be a http/https/ws/wss url, got "localhost:8881"`)
+}
+
+func TestValidateTierTokenCooldowns(t *testing.T) {
+	config := defaultConfig()
+	config.Axiom.AxiomAddr = "http://localhost:8881"
+	// 单独看 token 和 tier 的冷却都合法，tier 切换到 sliding 后继承了 token 的 period
+	config.Axiom.TokenCooldowns = map[string]Cooldown{"usdt": {Mode: "calendar", Period: -time.Hour}}
+	config.Axiom.Tiers = []Tier{{Name: "ci", Cooldown: Cooldown{Mode: "sliding"}}}
+
+	err := config.Validate()
+	require.NotNil(t, err)
+	require.Contains(t, fields(err), "axiom.tiers[0].token_cooldowns.usdt.period")
+	require.NotContains(t, fields(err), "axiom.token_cooldowns.usdt.period")
+	require.NotContains(t, fields(err), "axiom.tiers[0].cooldown.period")
+}
+
+func TestValidateListenerPorts(t *testing.T) {
+	config := defaultConfig()
+	config.Axiom.AxiomAddr = "http://localhost:8881"
+	config.Network.Port = "8080"
+	config.Network.TLS = TLS{Enable: true, RedirectPort: "9090"}
+	config.Admin = Admin{Enable: true, Port: "9091", Tokens: []string{"secret"}}
+	config.GRPC = GRPC{Enable: true, Port: "9091"}
+	config.Metrics = Metrics{Enable: true, Port: "9090"}
+
+	err := config.Validate()
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "grpc.port: must differ from admin.port 9091")
+	require.Contains(t, err.Error(), "metrics.port: must differ from network.tls.redirect_port 9090")
+	require.NotContains(t, fields(err), "admin.port")
+	require.NotContains(t, fields(err), "network.tls.redirect_port")
+}
+
+func fields(err error) []string {
+	var fields []string
+	for _, e := range err.(*ValidationError).Errors {
+		fields = append(fields, e.Field)
+	}
+	return fields
+}
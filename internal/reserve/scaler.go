@@ -0,0 +1,102 @@
+package reserve
+
+import (
+	"errors"
+	"faucet/internal/repo"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	CurveLinear = "linear"
+	CurveStep   = "step"
+)
+
+var ErrTooLow = errors.New("faucet reserves too low")
+
+// Scaler 根据水龙头当前余额和近期发放速率缩放每次领取的数量，余额越低发得越少
+type Scaler struct {
+	curve       string
+	lowBalance  float64
+	highBalance float64
+	minAmount   float64
+	steps       []repo.AmountStep
+	burnWindow  time.Duration
+	runway      time.Duration
+}
+
+func NewScaler(cfg repo.DynamicAmount) (*Scaler, error) {
+	s := &Scaler{
+		curve:       strings.ToLower(cfg.Curve),
+		lowBalance:  cfg.LowBalance,
+		highBalance: cfg.HighBalance,
+		minAmount:   cfg.MinAmount,
+		burnWindow:  cfg.BurnWindow,
+		runway:      cfg.Runway,
+	}
+	switch s.curve {
+	case CurveLinear:
+		if s.highBalance <= s.lowBalance {
+			return nil, fmt.Errorf("high_balance must be greater than low_balance")
+		}
+	case CurveStep:
+		if len(cfg.Steps) == 0 {
+			return nil, fmt.Errorf("step curve requires at least one step")
+		}
+		s.steps = append(s.steps, cfg.Steps...)
+		sort.Slice(s.steps, func(i, j int) bool {
+			return s.steps[i].Balance > s.steps[j].Balance
+		})
+	default:
+		return nil, fmt.Errorf("not support amount curve: %s", cfg.Curve)
+	}
+	if s.burnWindow <= 0 {
+		s.burnWindow = time.Hour
+	}
+	return s, nil
+}
+
+// BurnWindow 估算发放速率使用的时间窗口
+func (s *Scaler) BurnWindow() time.Duration {
+	return s.burnWindow
+}
+
+// Scale base 为未缩放的数量，balance 为水龙头余额，burned 为最近 BurnWindow 内的发放量。
+// 按当前速率预留 runway 时间的发放量后，用剩余的有效余额套用曲线，结果不超过 base
+func (s *Scaler) Scale(base float64, balance float64, burned float64) (float64, error) {
+	effective := balance
+	if s.runway > 0 {
+		effective -= burned * float64(s.runway) / float64(s.burnWindow)
+	}
+
+	var scaled float64
+	switch s.curve {
+	case CurveLinear:
+		switch {
+		case effective >= s.highBalance:
+			scaled = base
+		case effective <= s.lowBalance:
+			scaled = s.minAmount
+		default:
+			ratio := (effective - s.lowBalance) / (s.highBalance - s.lowBalance)
+			scaled = s.minAmount + (base-s.minAmount)*ratio
+		}
+	case CurveStep:
+		for _, step := range s.steps {
+			if effective >= step.Balance {
+				scaled = step.Amount
+				break
+			}
+		}
+	}
+
+	if scaled > base {
+		scaled = base
+	}
+	if scaled <= 0 {
+		return 0, ErrTooLow
+	}
+	return scaled, nil
+}
@@ -0,0 +1,98 @@
+package reserve
+
+import (
+	"faucet/internal/repo"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinear(t *testing.T) {
+	s, err := NewScaler(repo.DynamicAmount{
+		Curve:       CurveLinear,
+		LowBalance:  100,
+		HighBalance: 1100,
+		MinAmount:   0.1,
+	})
+	require.Nil(t, err)
+
+	tests := []struct {
+		balance float64
+		want    float64
+	}{
+		{2000, 1},
+		{1100, 1},
+		{600, 0.55},
+		{100, 0.1},
+		{10, 0.1},
+	}
+	for _, tt := range tests {
+		got, err := s.Scale(1, tt.balance, 0)
+		require.Nil(t, err)
+		require.InDelta(t, tt.want, got, 1e-9)
+	}
+}
+
+func TestStep(t *testing.T) {
+	s, err := NewScaler(repo.DynamicAmount{
+		Curve: CurveStep,
+		Steps: []repo.AmountStep{
+			{Balance: 100, Amount: 0.1},
+			{Balance: 1000, Amount: 0.5},
+			{Balance: 500, Amount: 0.25},
+		},
+	})
+	require.Nil(t, err)
+
+	tests := []struct {
+		balance float64
+		want    float64
+	}{
+		{5000, 0.5},
+		{999, 0.25},
+		{500, 0.25},
+		{120, 0.1},
+	}
+	for _, tt := range tests {
+		got, err := s.Scale(0.5, tt.balance, 0)
+		require.Nil(t, err)
+		require.Equal(t, tt.want, got)
+	}
+
+	_, err = s.Scale(0.5, 99, 0)
+	require.ErrorIs(t, err, ErrTooLow)
+
+	// 阶梯数量不超过 base
+	got, err := s.Scale(0.2, 5000, 0)
+	require.Nil(t, err)
+	require.Equal(t, 0.2, got)
+}
+
+func TestBurnRate(t *testing.T) {
+	s, err := NewScaler(repo.DynamicAmount{
+		Curve:      CurveStep,
+		Steps:      []repo.AmountStep{{Balance: 1000, Amount: 1}, {Balance: 100, Amount: 0.1}},
+		BurnWindow: time.Hour,
+		Runway:     24 * time.Hour,
+	})
+	require.Nil(t, err)
+
+	got, err := s.Scale(1, 2000, 0)
+	require.Nil(t, err)
+	require.Equal(t, 1.0, got)
+
+	// 每小时发放 50，预留 24 小时后有效余额只剩 800
+	got, err = s.Scale(1, 2000, 50)
+	require.Nil(t, err)
+	require.Equal(t, 0.1, got)
+}
+
+func TestNewScalerInvalid(t *testing.T) {
+	_, err := NewScaler(repo.DynamicAmount{Curve: "exp"})
+	require.NotNil(t, err)
+	_, err = NewScaler(repo.DynamicAmount{Curve: CurveLinear, LowBalance: 10, HighBalance: 10})
+	require.NotNil(t, err)
+	_, err = NewScaler(repo.DynamicAmount{Curve: CurveStep})
+	require.NotNil(t, err)
+}
@@ -0,0 +1,38 @@
+package store
+
+// ACLEntry 黑白名单中的一个地址、ip 或网段
+type ACLEntry struct {
+	List      string `json:"list"`
+	Kind      string `json:"kind"`
+	Value     string `json:"value"`
+	Comment   string `json:"comment"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+type ACLRepository interface {
+	Put(entry *ACLEntry) error
+	Delete(list string, value string) error
+	List() ([]*ACLEntry, error)
+}
+
+type aclRepository struct {
+	backend Backend
+}
+
+func (r *aclRepository) Put(entry *ACLEntry) error {
+	return putJSON(r.backend, aclKey(entry.List, entry.Value), entry)
+}
+
+func (r *aclRepository) Delete(list string, value string) error {
+	return r.backend.Delete(aclKey(list, value))
+}
+
+func (r *aclRepository) List() ([]*ACLEntry, error) {
+	var entries []*ACLEntry
+	err := listJSON(r.backend, aclPrefix(), func() interface{} {
+		entry := &ACLEntry{}
+		entries = append(entries, entry)
+		return entry
+	})
+	return entries, err
+}
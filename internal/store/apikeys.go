@@ -0,0 +1,65 @@
+package store
+
+import (
+	"strconv"
+	"time"
+)
+
+// APIKey 只保存 api key 的 sha256
+type APIKey struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Tier      string `json:"tier"`
+	Hash      string `json:"hash"`
+	CreatedAt int64  `json:"createdAt"`
+	Revoked   bool   `json:"revoked"`
+}
+
+type APIKeyRepository interface {
+	// Get 不存在时返回 nil, nil
+	Get(hash string) (*APIKey, error)
+	Put(key *APIKey) error
+	List() ([]*APIKey, error)
+	// Usage 和 SetUsage 读写 key 在 day 当天（UTC）已经领取的数量
+	Usage(hash string, day time.Time) (float64, error)
+	SetUsage(hash string, day time.Time, amount float64) error
+}
+
+type apiKeyRepository struct {
+	backend Backend
+}
+
+func (r *apiKeyRepository) Get(hash string) (*APIKey, error) {
+	key := &APIKey{}
+	ok, err := getJSON(r.backend, apiKeyKey(hash), key)
+	if !ok {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (r *apiKeyRepository) Put(key *APIKey) error {
+	return putJSON(r.backend, apiKeyKey(key.Hash), key)
+}
+
+func (r *apiKeyRepository) List() ([]*APIKey, error) {
+	var keys []*APIKey
+	err := listJSON(r.backend, apiKeyKey(""), func() interface{} {
+		key := &APIKey{}
+		keys = append(keys, key)
+		return key
+	})
+	return keys, err
+}
+
+func (r *apiKeyRepository) Usage(hash string, day time.Time) (float64, error) {
+	value, err := r.backend.Get(apiKeyUsageKey(hash, day))
+	if err != nil || value == nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(string(value), 64)
+}
+
+func (r *apiKeyRepository) SetUsage(hash string, day time.Time, amount float64) error {
+	return r.backend.Put(apiKeyUsageKey(hash, day), []byte(strconv.FormatFloat(amount, 'f', -1, 64)))
+}
@@ -0,0 +1,156 @@
+package store
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AuditEntry 审计账本中的一条记录，每次领取尝试写入一条，写入后不再修改
+type AuditEntry struct {
+	Seq       uint64  `json:"seq"`
+	Time      int64   `json:"time"`
+	Source    string  `json:"source"`
+	Net       string  `json:"net"`
+	Address   string  `json:"address"`
+	IP        string  `json:"ip"`
+	UserAgent string  `json:"userAgent"`
+	APIKey    string  `json:"apiKey"`
+	Decision  string  `json:"decision"`
+	Reason    string  `json:"reason"`
+	TxHash    string  `json:"txHash"`
+	Amount    float64 `json:"amount"`
+	GasUsed   uint64  `json:"gasUsed"`
+	Status    string  `json:"status"`
+	PrevHash  string  `json:"prevHash"`
+	Hash      string  `json:"hash"`
+}
+
+type AuditRepository interface {
+	// Last 返回最后一条记录，账本为空时返回 nil, nil
+	Last() (*AuditEntry, error)
+	// Append 写入 entry，调用方负责分配连续的 Seq
+	Append(entry *AuditEntry) error
+	// Range 按时间顺序遍历 Time 在 [from, to) 内的记录，fn 返回 false 时停止
+	Range(from time.Time, to time.Time, fn func(entry *AuditEntry) bool) error
+	// Scan 按 Seq 顺序遍历全部记录
+	Scan(fn func(entry *AuditEntry) bool) error
+	// FindTx 返回 TxHash 对应的最后一条记录，没有记录过该交易时返回 nil, nil
+	FindTx(txHash string) (*AuditEntry, error)
+}
+
+type auditRepository struct {
+	backend Backend
+}
+
+func (r *auditRepository) Last() (*AuditEntry, error) {
+	value, err := r.backend.Get([]byte(auditHeadKey))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	seq, err := strconv.ParseUint(string(value), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit head %q: %w", value, err)
+	}
+	return r.get(seq)
+}
+
+func (r *auditRepository) get(seq uint64) (*AuditEntry, error) {
+	entry := &AuditEntry{}
+	ok, err := getJSON(r.backend, auditKey(seq), entry)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("audit entry %d not found", seq)
+	}
+	return entry, nil
+}
+
+// Append 依次写入记录、索引和 head，中途失败时 head 不变，下次写入会覆盖这条不完整的记录
+func (r *auditRepository) Append(entry *AuditEntry) error {
+	if err := putJSON(r.backend, auditKey(entry.Seq), entry); err != nil {
+		return err
+	}
+	if err := r.backend.Put(auditTimeKey(entry.Time, entry.Seq), nil); err != nil {
+		return err
+	}
+	if entry.TxHash != "" {
+		if err := r.backend.Put(auditTxKey(entry.TxHash), []byte(strconv.FormatUint(entry.Seq, 10))); err != nil {
+			return err
+		}
+	}
+	return r.backend.Put([]byte(auditHeadKey), []byte(strconv.FormatUint(entry.Seq, 10)))
+}
+
+func (r *auditRepository) Range(from time.Time, to time.Time, fn func(entry *AuditEntry) bool) error {
+	head, err := r.Last()
+	if err != nil || head == nil {
+		return err
+	}
+	var seqs []uint64
+	err = r.backend.Iterate(auditTimeKey(from.UnixNano(), 0), auditTimeKey(to.UnixNano(), 0), func(key []byte, value []byte) bool {
+		idx := strings.LastIndex(string(key), "/")
+		seq, err := strconv.ParseUint(string(key[idx+1:]), 10, 64)
+		// 跳过 head 之后未写完的记录
+		if err == nil && seq <= head.Seq {
+			seqs = append(seqs, seq)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	for _, seq := range seqs {
+		entry, err := r.get(seq)
+		if err != nil {
+			return err
+		}
+		if !fn(entry) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *auditRepository) Scan(fn func(entry *AuditEntry) bool) error {
+	head, err := r.Last()
+	if err != nil || head == nil {
+		return err
+	}
+	for seq := uint64(1); seq <= head.Seq; seq++ {
+		entry, err := r.get(seq)
+		if err != nil {
+			return err
+		}
+		if !fn(entry) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *auditRepository) FindTx(txHash string) (*AuditEntry, error) {
+	value, err := r.backend.Get(auditTxKey(txHash))
+	if err != nil || value == nil {
+		return nil, err
+	}
+	seq, err := strconv.ParseUint(string(value), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid audit tx index %q: %w", value, err)
+	}
+	head, err := r.Last()
+	if err != nil {
+		return nil, err
+	}
+	// 索引指向未写完、随后被覆盖的记录时视为不存在
+	if head == nil || seq > head.Seq {
+		return nil, nil
+	}
+	entry, err := r.get(seq)
+	if err != nil || !strings.EqualFold(entry.TxHash, txHash) {
+		return nil, err
+	}
+	return entry, nil
+}
@@ -0,0 +1,42 @@
+package store
+
+// Backend 有序键值存储，store 中的各个仓库只依赖这个接口，可以替换为其他嵌入式存储
+type Backend interface {
+	// Get key 不存在时返回 nil, nil
+	Get(key []byte) ([]byte, error)
+	Put(key []byte, value []byte) error
+	Delete(key []byte) error
+	// Iterate 按 key 的字典序遍历 [start, end)，end 为 nil 时遍历到末尾，fn 返回 false 时停止
+	Iterate(start []byte, end []byte, fn func(key []byte, value []byte) bool) error
+	// Compact 整理 [start, end) 区间，回收已删除数据占用的空间
+	Compact(start []byte, end []byte) error
+	// Snapshot 返回当前时刻的只读视图，之后的写入对其不可见
+	Snapshot() (Snapshot, error)
+	Close() error
+}
+
+// Snapshot 后端某一时刻的只读视图，用完后需要 Release
+type Snapshot interface {
+	Iterate(start []byte, end []byte, fn func(key []byte, value []byte) bool) error
+	Release()
+}
+
+// prefixEnd 返回以 prefix 开头的 key 的上界
+func prefixEnd(prefix []byte) []byte {
+	end := append([]byte(nil), prefix...)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return nil
+}
+
+func compactPrefix(b Backend, prefix []byte) error {
+	return b.Compact(prefix, prefixEnd(prefix))
+}
+
+func iteratePrefix(b Backend, prefix []byte, fn func(key []byte, value []byte) bool) error {
+	return b.Iterate(prefix, prefixEnd(prefix), fn)
+}
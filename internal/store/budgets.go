@@ -0,0 +1,72 @@
+package store
+
+import (
+	"strconv"
+)
+
+// BudgetRepository 按时间桶累计各个 net 的发放量，bucket 为单调递增的桶序号
+type BudgetRepository interface {
+	Get(net string, bucket int64) (float64, error)
+	// Set amount 不大于 0 时删除该桶
+	Set(net string, bucket int64, amount float64) error
+	// Sum 统计 [from, to) 内各桶的发放量
+	Sum(net string, from int64, to int64) (float64, error)
+	// Prune 删除序号小于 before 的桶
+	Prune(net string, before int64) error
+}
+
+type budgetRepository struct {
+	backend Backend
+}
+
+func (r *budgetRepository) Get(net string, bucket int64) (float64, error) {
+	value, err := r.backend.Get(budgetKey(net, bucket))
+	if err != nil || value == nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(string(value), 64)
+}
+
+func (r *budgetRepository) Set(net string, bucket int64, amount float64) error {
+	if amount <= 0 {
+		return r.backend.Delete(budgetKey(net, bucket))
+	}
+	return r.backend.Put(budgetKey(net, bucket), []byte(strconv.FormatFloat(amount, 'f', -1, 64)))
+}
+
+func (r *budgetRepository) Sum(net string, from int64, to int64) (float64, error) {
+	var (
+		total    float64
+		parseErr error
+	)
+	err := r.backend.Iterate(budgetKey(net, from), budgetKey(net, to), func(key []byte, value []byte) bool {
+		amount, err := strconv.ParseFloat(string(value), 64)
+		if err != nil {
+			parseErr = err
+			return false
+		}
+		total += amount
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return total, parseErr
+}
+
+func (r *budgetRepository) Prune(net string, before int64) error {
+	var expired [][]byte
+	err := r.backend.Iterate(budgetKey(net, 0), budgetKey(net, before), func(key []byte, value []byte) bool {
+		expired = append(expired, key)
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range expired {
+		if err := r.backend.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
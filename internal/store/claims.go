@@ -0,0 +1,72 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Claim 地址最近一次确认上链的领取记录
+type Claim struct {
+	SendTxTime int64   `json:"sendTxTime"`
+	TxHash     string  `json:"txHash"`
+	Amount     float64 `json:"amount"`
+	// History 冷却窗口内每次领取的时间，旧记录没有该字段时以 SendTxTime 为准
+	History []int64 `json:"history,omitempty"`
+}
+
+type ClaimRepository interface {
+	// Get 没有领取记录时返回 nil, nil
+	Get(net string, typ string, address string) (*Claim, error)
+	Put(net string, typ string, address string, claim *Claim) error
+	Delete(net string, typ string, address string) error
+	// Scan 按 key 顺序遍历所有领取记录，fn 返回 false 时停止
+	Scan(fn func(net string, typ string, address string, claim *Claim) bool) error
+	// Compact 整理领取记录所在的区间
+	Compact() error
+}
+
+type claimRepository struct {
+	backend Backend
+}
+
+func (r *claimRepository) Get(net string, typ string, address string) (*Claim, error) {
+	claim := &Claim{}
+	ok, err := getJSON(r.backend, claimKey(net, typ, address), claim)
+	if !ok {
+		return nil, err
+	}
+	return claim, nil
+}
+
+func (r *claimRepository) Put(net string, typ string, address string, claim *Claim) error {
+	return putJSON(r.backend, claimKey(net, typ, address), claim)
+}
+
+func (r *claimRepository) Delete(net string, typ string, address string) error {
+	return r.backend.Delete(claimKey(net, typ, address))
+}
+
+func (r *claimRepository) Scan(fn func(net string, typ string, address string, claim *Claim) bool) error {
+	var scanErr error
+	err := iteratePrefix(r.backend, []byte(nsClaim), func(key []byte, value []byte) bool {
+		parts := strings.SplitN(strings.TrimPrefix(string(key), nsClaim), "/", 3)
+		if len(parts) != 3 {
+			scanErr = fmt.Errorf("invalid claim key: %s", key)
+			return false
+		}
+		claim := &Claim{}
+		if err := unmarshal(key, value, claim); err != nil {
+			scanErr = err
+			return false
+		}
+		return fn(parts[0], parts[1], parts[2], claim)
+	})
+	if err != nil {
+		return err
+	}
+	return scanErr
+}
+
+func (r *claimRepository) Compact() error {
+	return compactPrefix(r.backend, []byte(nsClaim))
+}
@@ -0,0 +1,166 @@
+package store
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// dumpHeader 导出文件的第一行，记录数据对应的 schema 版本
+type dumpHeader struct {
+	SchemaVersion int   `json:"schemaVersion"`
+	ExportedAt    int64 `json:"exportedAt"`
+}
+
+// dumpRecord 导出文件中的一条记录，合法的 JSON 值原样保存在 value 中，否则 base64 编码后保存在 data 中
+type dumpRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value,omitempty"`
+	Data  []byte          `json:"data,omitempty"`
+}
+
+var ErrNotEmpty = errors.New("store is not empty")
+
+// maxDumpLine 单条记录的上限，远大于任何业务记录
+const maxDumpLine = 16 * 1024 * 1024
+
+func (s *store) Export(w io.Writer) (int, error) {
+	version, err := readSchemaVersion(s.backend)
+	if err != nil {
+		return 0, err
+	}
+	snap, err := s.backend.Snapshot()
+	if err != nil {
+		return 0, err
+	}
+	defer snap.Release()
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	if err := enc.Encode(&dumpHeader{SchemaVersion: version, ExportedAt: time.Now().Unix()}); err != nil {
+		return 0, err
+	}
+	var (
+		count     int
+		encodeErr error
+	)
+	err = snap.Iterate(nil, nil, func(key []byte, value []byte) bool {
+		if bytes.HasPrefix(key, []byte(metaPrefix)) {
+			return true
+		}
+		record := &dumpRecord{Key: string(key)}
+		if json.Valid(value) {
+			record.Value = value
+		} else {
+			record.Data = value
+		}
+		if encodeErr = enc.Encode(record); encodeErr != nil {
+			return false
+		}
+		count++
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if encodeErr != nil {
+		return 0, encodeErr
+	}
+	return count, bw.Flush()
+}
+
+func (s *store) Import(r io.Reader) (int, error) {
+	empty := true
+	if err := s.backend.Iterate(nil, nil, func(key []byte, value []byte) bool {
+		if bytes.HasPrefix(key, []byte(metaPrefix)) {
+			return true
+		}
+		empty = false
+		return false
+	}); err != nil {
+		return 0, err
+	}
+	if !empty {
+		return 0, ErrNotEmpty
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxDumpLine)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("empty dump")
+	}
+	header := &dumpHeader{}
+	if err := json.Unmarshal(scanner.Bytes(), header); err != nil {
+		return 0, fmt.Errorf("invalid dump header: %w", err)
+	}
+	if header.SchemaVersion > SchemaVersion() {
+		return 0, fmt.Errorf("dump schema version %d is newer than supported version %d, please upgrade faucet", header.SchemaVersion, SchemaVersion())
+	}
+
+	var count int
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		record := &dumpRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return count, fmt.Errorf("invalid dump record at line %d: %w", count+2, err)
+		}
+		value := []byte(record.Value)
+		if record.Data != nil {
+			value = record.Data
+		}
+		if err := s.backend.Put([]byte(record.Key), value); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return count, err
+	}
+	// 旧版本的导出文件导入后按正常流程升级
+	if err := writeSchemaVersion(s.backend, header.SchemaVersion); err != nil {
+		return count, err
+	}
+	if _, err := migrate(s.backend); err != nil {
+		return count, err
+	}
+	return count, nil
+}
+
+// Backup 在线导出到 path，先写临时文件再重命名，不会留下不完整的备份
+func Backup(s Store, path string) (int, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, fmt.Errorf("create backup dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return 0, fmt.Errorf("create backup: %w", err)
+	}
+	count, err := s.Export(f)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return 0, fmt.Errorf("write backup: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return 0, err
+	}
+	return count, nil
+}
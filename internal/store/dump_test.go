@@ -0,0 +1,82 @@
+package store
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImport(t *testing.T) {
+	src := New(NewMemory())
+	_, err := src.Migrate()
+	require.Nil(t, err)
+	require.Nil(t, src.Claims().Put("axm", "native", "0xabc", &Claim{SendTxTime: 100, TxHash: "0x1", Amount: 0.5}))
+	require.Nil(t, src.Jobs().Put(&Job{Net: "axm", TxHash: "0x2"}))
+	require.Nil(t, src.Budgets().Set("axm", 10, 1.5))
+
+	var buf bytes.Buffer
+	count, err := src.Export(&buf)
+	require.Nil(t, err)
+	require.Equal(t, 3, count)
+	require.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 4)
+
+	dst, err := Open(filepath.Join(t.TempDir(), "store"))
+	require.Nil(t, err)
+	defer dst.Close()
+	count, err = dst.Import(bytes.NewReader(buf.Bytes()))
+	require.Nil(t, err)
+	require.Equal(t, 3, count)
+
+	claim, err := dst.Claims().Get("axm", "native", "0xabc")
+	require.Nil(t, err)
+	require.Equal(t, "0x1", claim.TxHash)
+	amount, err := dst.Budgets().Get("axm", 10)
+	require.Nil(t, err)
+	require.Equal(t, 1.5, amount)
+	version, err := dst.Version()
+	require.Nil(t, err)
+	require.Equal(t, SchemaVersion(), version)
+
+	// 非空库拒绝导入
+	_, err = dst.Import(bytes.NewReader(buf.Bytes()))
+	require.Equal(t, ErrNotEmpty, err)
+}
+
+func TestImportLegacyDump(t *testing.T) {
+	dump := `{"schemaVersion":0,"exportedAt":0}
+{"key":"axm0xabcdef0000000000000000000000000000000001-native","value":{"sendTxTime":100,"txHash":"0x1"}}
+{"key":"unknown","data":"eA=="}
+`
+	s := New(NewMemory())
+	count, err := s.Import(strings.NewReader(dump))
+	require.Nil(t, err)
+	require.Equal(t, 2, count)
+
+	claim, err := s.Claims().Get("axm", "native", "0xabcdef0000000000000000000000000000000001")
+	require.Nil(t, err)
+	require.Equal(t, "0x1", claim.TxHash)
+	version, err := s.Version()
+	require.Nil(t, err)
+	require.Equal(t, SchemaVersion(), version)
+}
+
+func TestBackup(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "store"))
+	require.Nil(t, err)
+	defer s.Close()
+	require.Nil(t, s.Jobs().Put(&Job{Net: "axm", TxHash: "0x1"}))
+
+	path := filepath.Join(t.TempDir(), "backups", "store.jsonl")
+	count, err := Backup(s, path)
+	require.Nil(t, err)
+	require.Equal(t, 1, count)
+	data, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.Contains(t, string(data), `"key":"v1/job/0x1"`)
+	_, err = os.Stat(path + ".tmp")
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,41 @@
+package store
+
+// Job 已广播、等待确认上链的领取交易
+type Job struct {
+	Net     string  `json:"net"`
+	Address string  `json:"address"`
+	TxHash  string  `json:"txHash"`
+	Amount  float64 `json:"amount"`
+	SentAt  int64   `json:"sentAt"`
+	// Tier 和 IP 用于确认后补记冷却和 ip 配额，IP 为空表示不计入 ip 配额
+	Tier string `json:"tier,omitempty"`
+	IP   string `json:"ip,omitempty"`
+}
+
+type JobRepository interface {
+	Put(job *Job) error
+	Delete(txHash string) error
+	List() ([]*Job, error)
+}
+
+type jobRepository struct {
+	backend Backend
+}
+
+func (r *jobRepository) Put(job *Job) error {
+	return putJSON(r.backend, jobKey(job.TxHash), job)
+}
+
+func (r *jobRepository) Delete(txHash string) error {
+	return r.backend.Delete(jobKey(txHash))
+}
+
+func (r *jobRepository) List() ([]*Job, error) {
+	var jobs []*Job
+	err := listJSON(r.backend, jobKey(""), func() interface{} {
+		job := &Job{}
+		jobs = append(jobs, job)
+		return job
+	})
+	return jobs, err
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 所有业务数据的 key 都在这里构造，格式为 <schema>/<namespace>/<字段>...，
+// 修改 key 的格式时需要升级 schemaPrefix 并在 migrate.go 中增加对应的迁移
+const (
+	schemaPrefix = "v1/"
+
+	nsClaim       = schemaPrefix + "claim/"
+	nsIPQuota     = schemaPrefix + "ipquota/"
+	nsJob         = schemaPrefix + "job/"
+	nsACL         = schemaPrefix + "acl/"
+	nsAPIKey      = schemaPrefix + "apikey/"
+	nsAPIKeyUsage = schemaPrefix + "apikey_usage/"
+	nsBudget      = schemaPrefix + "budget/"
+	nsAudit       = schemaPrefix + "audit/"
+	nsAuditTime   = schemaPrefix + "audit_time/"
+	nsAuditTx     = schemaPrefix + "audit_tx/"
+	auditHeadKey  = schemaPrefix + "audit_head"
+
+	// metaPrefix 存放 schema 版本等元数据，不随 schema 变化
+	metaPrefix       = "meta/"
+	schemaVersionKey = metaPrefix + "schema_version"
+
+	dayLayout = "2006-01-02"
+)
+
+// claimKey 和 ipQuotaKey 中的 net 统一为小写，大小写不同的 net 共用同一份冷却和配额
+func claimKey(net string, typ string, address string) []byte {
+	return []byte(nsClaim + strings.ToLower(net) + "/" + typ + "/" + address)
+}
+
+func ipQuotaKey(net string, ip string, day time.Time) []byte {
+	return []byte(nsIPQuota + strings.ToLower(net) + "/" + day.UTC().Format(dayLayout) + "/" + ip)
+}
+
+func jobKey(txHash string) []byte {
+	return []byte(nsJob + txHash)
+}
+
+func aclPrefix() []byte {
+	return []byte(nsACL)
+}
+
+func aclKey(list string, value string) []byte {
+	return []byte(nsACL + list + "/" + value)
+}
+
+func apiKeyKey(hash string) []byte {
+	return []byte(nsAPIKey + hash)
+}
+
+func apiKeyUsageKey(hash string, day time.Time) []byte {
+	return []byte(nsAPIKeyUsage + hash + "/" + day.UTC().Format(dayLayout))
+}
+
+// budgetKey 桶序号补零到固定宽度，保证字典序与时间顺序一致
+func budgetKey(net string, bucket int64) []byte {
+	return []byte(fmt.Sprintf("%s%s/%012d", nsBudget, net, bucket))
+}
+
+func auditKey(seq uint64) []byte {
+	return []byte(fmt.Sprintf("%s%020d", nsAudit, seq))
+}
+
+// auditTimeKey 按时间排序的索引，同一纳秒内按 seq 排序
+func auditTimeKey(nano int64, seq uint64) []byte {
+	return []byte(fmt.Sprintf("%s%020d/%020d", nsAuditTime, nano, seq))
+}
+
+// auditTxKey 交易哈希到最后一条相关记录的索引，哈希统一为小写
+func auditTxKey(txHash string) []byte {
+	return []byte(nsAuditTx + strings.ToLower(txHash))
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/iterator"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+type levelDB struct {
+	db *leveldb.DB
+}
+
+// NewLevelDB 打开 path 下的 leveldb
+func NewLevelDB(path string) (Backend, error) {
+	db, err := leveldb.OpenFile(path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &levelDB{db: db}, nil
+}
+
+func (l *levelDB) Get(key []byte) ([]byte, error) {
+	value, err := l.db.Get(key, nil)
+	if err == leveldb.ErrNotFound {
+		return nil, nil
+	}
+	return value, err
+}
+
+func (l *levelDB) Put(key []byte, value []byte) error {
+	return l.db.Put(key, value, nil)
+}
+
+func (l *levelDB) Delete(key []byte) error {
+	return l.db.Delete(key, nil)
+}
+
+func (l *levelDB) Iterate(start []byte, end []byte, fn func(key []byte, value []byte) bool) error {
+	return iterate(l.db.NewIterator(&util.Range{Start: start, Limit: end}, nil), fn)
+}
+
+func (l *levelDB) Compact(start []byte, end []byte) error {
+	return l.db.CompactRange(util.Range{Start: start, Limit: end})
+}
+
+func (l *levelDB) Snapshot() (Snapshot, error) {
+	snap, err := l.db.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return &levelSnapshot{snap: snap}, nil
+}
+
+func (l *levelDB) Close() error {
+	return l.db.Close()
+}
+
+type levelSnapshot struct {
+	snap *leveldb.Snapshot
+}
+
+func (s *levelSnapshot) Iterate(start []byte, end []byte, fn func(key []byte, value []byte) bool) error {
+	return iterate(s.snap.NewIterator(&util.Range{Start: start, Limit: end}, nil), fn)
+}
+
+func (s *levelSnapshot) Release() {
+	s.snap.Release()
+}
+
+func iterate(it iterator.Iterator, fn func(key []byte, value []byte) bool) error {
+	defer it.Release()
+	for it.Next() {
+		if !fn(append([]byte(nil), it.Key()...), append([]byte(nil), it.Value()...)) {
+			break
+		}
+	}
+	return it.Error()
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+)
+
+type memory struct {
+	lock sync.RWMutex
+	kv   map[string][]byte
+}
+
+// NewMemory 内存后端，用于测试以及不需要持久化的场景
+func NewMemory() Backend {
+	return &memory{kv: make(map[string][]byte)}
+}
+
+func (m *memory) Get(key []byte) ([]byte, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	value, ok := m.kv[string(key)]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), value...), nil
+}
+
+func (m *memory) Put(key []byte, value []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.kv[string(key)] = append([]byte(nil), value...)
+	return nil
+}
+
+func (m *memory) Delete(key []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	delete(m.kv, string(key))
+	return nil
+}
+
+// Iterate 先在锁内拷贝区间内的数据，回调中可以安全地读写
+func (m *memory) Iterate(start []byte, end []byte, fn func(key []byte, value []byte) bool) error {
+	m.lock.RLock()
+	var keys []string
+	for k := range m.kv {
+		if bytes.Compare([]byte(k), start) >= 0 && (end == nil || bytes.Compare([]byte(k), end) < 0) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	values := make([][]byte, len(keys))
+	for i, k := range keys {
+		values[i] = append([]byte(nil), m.kv[k]...)
+	}
+	m.lock.RUnlock()
+
+	for i, k := range keys {
+		if !fn([]byte(k), values[i]) {
+			break
+		}
+	}
+	return nil
+}
+
+func (m *memory) Compact(start []byte, end []byte) error {
+	return nil
+}
+
+// Snapshot 拷贝一份当前数据
+func (m *memory) Snapshot() (Snapshot, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	snap := &memory{kv: make(map[string][]byte, len(m.kv))}
+	for k, v := range m.kv {
+		snap.kv[k] = append([]byte(nil), v...)
+	}
+	return snap, nil
+}
+
+func (m *memory) Release() {}
+
+func (m *memory) Close() error {
+	return nil
+}
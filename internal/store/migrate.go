@@ -0,0 +1,171 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// migration 把数据从 version-1 升级到 version。up 必须可以重复执行：
+// 中途失败时 schema 版本不会更新，下次启动会从头再跑一遍
+type migration struct {
+	version     int
+	description string
+	up          func(b Backend) (int, error)
+}
+
+var migrations = []migration{
+	{version: 1, description: "move legacy claim keys into the versioned namespace", up: namespaceLegacyKeys},
+	{version: 2, description: "index audit entries by tx hash", up: indexAuditTxs},
+}
+
+// SchemaVersion 当前代码使用的 schema 版本
+func SchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// MigrationResult 一次 Migrate 的结果
+type MigrationResult struct {
+	From     int
+	To       int
+	Migrated int
+}
+
+func readSchemaVersion(b Backend) (int, error) {
+	value, err := b.Get([]byte(schemaVersionKey))
+	if err != nil || value == nil {
+		return 0, err
+	}
+	version, err := strconv.Atoi(string(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid schema version %q: %w", value, err)
+	}
+	return version, nil
+}
+
+func writeSchemaVersion(b Backend, version int) error {
+	return b.Put([]byte(schemaVersionKey), []byte(strconv.Itoa(version)))
+}
+
+// migrate 依次执行高于当前版本的迁移，每完成一个就写入新的版本号
+func migrate(b Backend) (*MigrationResult, error) {
+	current, err := readSchemaVersion(b)
+	if err != nil {
+		return nil, err
+	}
+	res := &MigrationResult{From: current, To: current}
+	if current > SchemaVersion() {
+		return nil, fmt.Errorf("store schema version %d is newer than supported version %d, please upgrade faucet", current, SchemaVersion())
+	}
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		n, err := m.up(b)
+		if err != nil {
+			return nil, fmt.Errorf("migrate store to version %d (%s): %w", m.version, m.description, err)
+		}
+		if err := writeSchemaVersion(b, m.version); err != nil {
+			return nil, err
+		}
+		res.To = m.version
+		res.Migrated += n
+	}
+	return res, nil
+}
+
+// legacyClaim 0.x 版本的领取记录 key：按请求原样拼接的 net、地址和 token 类型，需要不区分大小写地匹配
+var legacyClaim = regexp.MustCompile(`^(?i)([a-z]+)(0x[0-9a-f]{40})-(\w+)$`)
+
+// namespaceLegacyKeys 把 0.x 版本的领取记录改写为 v1 格式，0.x 只写入过领取记录，其他 key 保持不动
+func namespaceLegacyKeys(b Backend) (int, error) {
+	type rename struct {
+		from []byte
+		to   []byte
+	}
+	var renames []rename
+	// 大小写不同的旧 key 会落到同一个新 key，只保留最近的一次领取
+	merged := make(map[string][]byte)
+	err := b.Iterate(nil, nil, func(key []byte, value []byte) bool {
+		to := legacyClaimKey(string(key))
+		if to == "" {
+			return true
+		}
+		if prev, ok := merged[to]; ok {
+			value = latestClaim(prev, value)
+		}
+		merged[to] = append([]byte(nil), value...)
+		renames = append(renames, rename{from: append([]byte(nil), key...), to: []byte(to)})
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	// 先写新 key 再删旧 key，中断后重跑不会丢数据
+	for _, r := range renames {
+		if err := b.Put(r.to, merged[string(r.to)]); err != nil {
+			return 0, err
+		}
+		if err := b.Delete(r.from); err != nil {
+			return 0, err
+		}
+	}
+	return len(renames), nil
+}
+
+// latestClaim 返回 SendTxTime 较大的一条领取记录
+func latestClaim(a []byte, b []byte) []byte {
+	var ca, cb Claim
+	if json.Unmarshal(a, &ca) != nil {
+		return b
+	}
+	if json.Unmarshal(b, &cb) != nil || ca.SendTxTime >= cb.SendTxTime {
+		return a
+	}
+	return b
+}
+
+func legacyClaimKey(key string) string {
+	if strings.HasPrefix(key, schemaPrefix) || strings.HasPrefix(key, metaPrefix) {
+		return ""
+	}
+	m := legacyClaim.FindStringSubmatch(key)
+	if m == nil {
+		return ""
+	}
+	return nsClaim + strings.ToLower(m[1]) + "/" + m[3] + "/" + strings.ToLower(m[2])
+}
+
+// indexAuditTxs 为已有的审计记录补上交易哈希索引，按 seq 顺序写入，同一笔交易保留最后一条
+func indexAuditTxs(b Backend) (int, error) {
+	type index struct {
+		txHash string
+		seq    uint64
+	}
+	var indexes []index
+	var decodeErr error
+	err := iteratePrefix(b, []byte(nsAudit), func(key []byte, value []byte) bool {
+		entry := &AuditEntry{}
+		if decodeErr = json.Unmarshal(value, entry); decodeErr != nil {
+			return false
+		}
+		if entry.TxHash != "" {
+			indexes = append(indexes, index{txHash: entry.TxHash, seq: entry.Seq})
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	if decodeErr != nil {
+		return 0, decodeErr
+	}
+	for _, idx := range indexes {
+		if err := b.Put(auditTxKey(idx.txHash), []byte(strconv.FormatUint(idx.seq, 10))); err != nil {
+			return 0, err
+		}
+	}
+	return len(indexes), nil
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateLegacyKeys(t *testing.T) {
+	address := "0xabcdef0000000000000000000000000000000001"
+	legacy := map[string]string{
+		"axm" + address + "-native":                            `{"sendTxTime":100,"txHash":"0x1","amount":0.5}`,
+		"AXM0x" + strings.ToUpper(address[2:]) + "-native":     `{"sendTxTime":50,"txHash":"0x0","amount":0.5}`,
+		"axm0xabcdef0000000000000000000000000000000002-native": `{"sendTxTime":60,"txHash":"0x2","amount":0.5}`,
+		"unknown": "x",
+	}
+	backend := NewMemory()
+	for k, v := range legacy {
+		require.Nil(t, backend.Put([]byte(k), []byte(v)))
+	}
+	s := New(backend)
+	version, err := s.Version()
+	require.Nil(t, err)
+	require.Equal(t, 0, version)
+
+	res, err := s.Migrate()
+	require.Nil(t, err)
+	require.Equal(t, &MigrationResult{From: 0, To: SchemaVersion(), Migrated: 3}, res)
+
+	// 大小写不同的旧 key 合并后保留最近的一次领取
+	claim, err := s.Claims().Get("axm", "native", address)
+	require.Nil(t, err)
+	require.Equal(t, "0x1", claim.TxHash)
+	claim, err = s.Claims().Get("axm", "native", "0xabcdef0000000000000000000000000000000002")
+	require.Nil(t, err)
+	require.Equal(t, "0x2", claim.TxHash)
+
+	// 旧 key 已删除，无法识别的 key 保持不动
+	value, err := backend.Get([]byte("axm" + address + "-native"))
+	require.Nil(t, err)
+	require.Nil(t, value)
+	value, err = backend.Get([]byte("unknown"))
+	require.Nil(t, err)
+	require.Equal(t, "x", string(value))
+
+	// 再次执行不做任何修改
+	res, err = s.Migrate()
+	require.Nil(t, err)
+	require.Equal(t, &MigrationResult{From: SchemaVersion(), To: SchemaVersion()}, res)
+}
+
+func TestMigrateIndexesAuditTxs(t *testing.T) {
+	backend := NewMemory()
+	require.Nil(t, writeSchemaVersion(backend, 1))
+	for seq, txHash := range []string{"0x1", "", "0x1"} {
+		require.Nil(t, putJSON(backend, auditKey(uint64(seq+1)), &AuditEntry{Seq: uint64(seq + 1), TxHash: txHash}))
+	}
+	require.Nil(t, backend.Put([]byte(auditHeadKey), []byte("3")))
+
+	s := New(backend)
+	res, err := s.Migrate()
+	require.Nil(t, err)
+	require.Equal(t, &MigrationResult{From: 1, To: SchemaVersion(), Migrated: 2}, res)
+	entry, err := s.Audit().FindTx("0x1")
+	require.Nil(t, err)
+	require.Equal(t, uint64(3), entry.Seq)
+}
+
+func TestMigrateNewerSchema(t *testing.T) {
+	backend := NewMemory()
+	require.Nil(t, writeSchemaVersion(backend, SchemaVersion()+1))
+	_, err := New(backend).Migrate()
+	require.NotNil(t, err)
+}
+
+func TestOpenStampsEmptyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store")
+	s, err := Open(path)
+	require.Nil(t, err)
+	version, err := s.Version()
+	require.Nil(t, err)
+	require.Equal(t, SchemaVersion(), version)
+	require.Nil(t, s.Close())
+}
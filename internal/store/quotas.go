@@ -0,0 +1,69 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IPQuotaRepository 按 UTC 自然日统计每个客户端 ip 的领取次数
+type IPQuotaRepository interface {
+	Count(net string, ip string, day time.Time) (int, error)
+	Incr(net string, ip string, day time.Time) (int, error)
+	// Prune 删除所有 net 中早于 before 当天的计数，返回删除的条数
+	Prune(before time.Time) (int, error)
+	// Compact 整理计数所在的区间
+	Compact() error
+}
+
+type ipQuotaRepository struct {
+	backend Backend
+	// lock 串行化 Incr 的读-改-写，避免并发发放丢失计数
+	lock sync.Mutex
+}
+
+func (r *ipQuotaRepository) Count(net string, ip string, day time.Time) (int, error) {
+	value, err := r.backend.Get(ipQuotaKey(net, ip, day))
+	if err != nil || value == nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(value))
+}
+
+func (r *ipQuotaRepository) Incr(net string, ip string, day time.Time) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	count, err := r.Count(net, ip, day)
+	if err != nil {
+		return 0, err
+	}
+	count++
+	return count, r.backend.Put(ipQuotaKey(net, ip, day), []byte(strconv.Itoa(count)))
+}
+
+func (r *ipQuotaRepository) Prune(before time.Time) (int, error) {
+	cutoff := before.UTC().Format(dayLayout)
+	var expired [][]byte
+	err := iteratePrefix(r.backend, []byte(nsIPQuota), func(key []byte, value []byte) bool {
+		// key 为 <net>/<date>/<ip>
+		parts := strings.SplitN(strings.TrimPrefix(string(key), nsIPQuota), "/", 3)
+		if len(parts) == 3 && parts[1] < cutoff {
+			expired = append(expired, key)
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	for _, key := range expired {
+		if err := r.backend.Delete(key); err != nil {
+			return 0, err
+		}
+	}
+	return len(expired), nil
+}
+
+func (r *ipQuotaRepository) Compact() error {
+	return compactPrefix(r.backend, []byte(nsIPQuota))
+}
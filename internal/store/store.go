@@ -0,0 +1,137 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Store 水龙头的业务数据，业务代码通过各个仓库读写，不直接拼接 key
+type Store interface {
+	Claims() ClaimRepository
+	IPQuotas() IPQuotaRepository
+	Jobs() JobRepository
+	ACLs() ACLRepository
+	APIKeys() APIKeyRepository
+	Budgets() BudgetRepository
+	Audit() AuditRepository
+	// Version 返回库中记录的 schema 版本，未迁移过的旧库为 0
+	Version() (int, error)
+	// Migrate 把库升级到 SchemaVersion，已经是最新版本时不做任何修改
+	Migrate() (*MigrationResult, error)
+	// Export 基于快照把所有记录以 JSONL 格式写入 w，运行中也可以调用，返回导出的条数
+	Export(w io.Writer) (int, error)
+	// Import 把 Export 的结果导入空库，并升级到 SchemaVersion
+	Import(r io.Reader) (int, error)
+	Close() error
+}
+
+type store struct {
+	backend  Backend
+	claims   *claimRepository
+	ipQuotas *ipQuotaRepository
+	jobs     *jobRepository
+	acls     *aclRepository
+	apiKeys  *apiKeyRepository
+	budgets  *budgetRepository
+	audit    *auditRepository
+}
+
+// New 基于任意后端构建 Store
+func New(backend Backend) Store {
+	return &store{
+		backend:  backend,
+		claims:   &claimRepository{backend: backend},
+		ipQuotas: &ipQuotaRepository{backend: backend},
+		jobs:     &jobRepository{backend: backend},
+		acls:     &aclRepository{backend: backend},
+		apiKeys:  &apiKeyRepository{backend: backend},
+		budgets:  &budgetRepository{backend: backend},
+		audit:    &auditRepository{backend: backend},
+	}
+}
+
+// Open 打开 path 下基于 leveldb 的 Store，新建的空库直接标记为最新的 schema 版本
+func Open(path string) (Store, error) {
+	backend, err := NewLevelDB(path)
+	if err != nil {
+		return nil, err
+	}
+	empty := true
+	if err := backend.Iterate(nil, nil, func(key []byte, value []byte) bool {
+		empty = false
+		return false
+	}); err != nil {
+		backend.Close()
+		return nil, err
+	}
+	if empty {
+		if err := writeSchemaVersion(backend, SchemaVersion()); err != nil {
+			backend.Close()
+			return nil, err
+		}
+	}
+	return New(backend), nil
+}
+
+func (s *store) Claims() ClaimRepository     { return s.claims }
+func (s *store) IPQuotas() IPQuotaRepository { return s.ipQuotas }
+func (s *store) Jobs() JobRepository         { return s.jobs }
+func (s *store) ACLs() ACLRepository         { return s.acls }
+func (s *store) APIKeys() APIKeyRepository   { return s.apiKeys }
+func (s *store) Budgets() BudgetRepository   { return s.budgets }
+func (s *store) Audit() AuditRepository      { return s.audit }
+
+func (s *store) Version() (int, error) {
+	return readSchemaVersion(s.backend)
+}
+
+func (s *store) Migrate() (*MigrationResult, error) {
+	return migrate(s.backend)
+}
+
+func (s *store) Close() error {
+	return s.backend.Close()
+}
+
+func getJSON(b Backend, key []byte, v interface{}) (bool, error) {
+	value, err := b.Get(key)
+	if err != nil || value == nil {
+		return false, err
+	}
+	if err := unmarshal(key, value, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func unmarshal(key []byte, value []byte, v interface{}) error {
+	if err := json.Unmarshal(value, v); err != nil {
+		return fmt.Errorf("unmarshal %s: %w", key, err)
+	}
+	return nil
+}
+
+func putJSON(b Backend, key []byte, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("json marshal failed: %w", err)
+	}
+	return b.Put(key, data)
+}
+
+// listJSON 反序列化 prefix 下的所有记录，newItem 返回新元素的指针
+func listJSON(b Backend, prefix []byte, newItem func() interface{}) error {
+	var decodeErr error
+	err := iteratePrefix(b, prefix, func(key []byte, value []byte) bool {
+		if err := unmarshal(key, value, newItem()); err != nil {
+			decodeErr = err
+			return false
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return decodeErr
+}
@@ -0,0 +1,185 @@
+package store
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// backends 每个用例在内存和 leveldb 两种后端上各跑一遍
+func backends(t *testing.T) map[string]Store {
+	ldb, err := Open(filepath.Join(t.TempDir(), "store"))
+	require.Nil(t, err)
+	t.Cleanup(func() { ldb.Close() })
+	return map[string]Store{
+		"memory":  New(NewMemory()),
+		"leveldb": ldb,
+	}
+}
+
+func TestClaims(t *testing.T) {
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			claim, err := s.Claims().Get("axm", "native", "0xabc")
+			require.Nil(t, err)
+			require.Nil(t, claim)
+
+			require.Nil(t, s.Claims().Put("axm", "native", "0xabc", &Claim{SendTxTime: 100, TxHash: "0x1", Amount: 0.5}))
+			claim, err = s.Claims().Get("axm", "native", "0xabc")
+			require.Nil(t, err)
+			require.Equal(t, &Claim{SendTxTime: 100, TxHash: "0x1", Amount: 0.5}, claim)
+
+			var scanned []string
+			require.Nil(t, s.Claims().Scan(func(net string, typ string, address string, claim *Claim) bool {
+				scanned = append(scanned, net+"/"+typ+"/"+address+"/"+claim.TxHash)
+				return true
+			}))
+			require.Equal(t, []string{"axm/native/0xabc/0x1"}, scanned)
+
+			// net 不区分大小写
+			claim, err = s.Claims().Get("AxM", "native", "0xabc")
+			require.Nil(t, err)
+			require.Equal(t, "0x1", claim.TxHash)
+
+			require.Nil(t, s.Claims().Delete("axm", "native", "0xabc"))
+			claim, err = s.Claims().Get("axm", "native", "0xabc")
+			require.Nil(t, err)
+			require.Nil(t, claim)
+		})
+	}
+}
+
+func TestIPQuotas(t *testing.T) {
+	day := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			for i := 1; i <= 3; i++ {
+				count, err := s.IPQuotas().Incr("axm", "10.0.0.1", day)
+				require.Nil(t, err)
+				require.Equal(t, i, count)
+			}
+			count, err := s.IPQuotas().Count("axm", "10.0.0.1", day.Add(24*time.Hour))
+			require.Nil(t, err)
+			require.Equal(t, 0, count)
+
+			_, err = s.IPQuotas().Incr("axm", "10.0.0.2", day.Add(24*time.Hour))
+			require.Nil(t, err)
+			pruned, err := s.IPQuotas().Prune(day.Add(24 * time.Hour))
+			require.Nil(t, err)
+			require.Equal(t, 1, pruned)
+			require.Nil(t, s.IPQuotas().Compact())
+			count, err = s.IPQuotas().Count("axm", "10.0.0.2", day.Add(24*time.Hour))
+			require.Nil(t, err)
+			require.Equal(t, 1, count)
+		})
+	}
+}
+
+func TestIPQuotaConcurrentIncr(t *testing.T) {
+	day := time.Date(2023, 8, 1, 23, 0, 0, 0, time.FixedZone("UTC-8", -8*3600))
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			var wg sync.WaitGroup
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := s.IPQuotas().Incr("axm", "10.0.0.1", day)
+					require.Nil(t, err)
+				}()
+			}
+			wg.Wait()
+			// 计数按 UTC 自然日归档，与时区无关
+			count, err := s.IPQuotas().Count("axm", "10.0.0.1", time.Date(2023, 8, 2, 1, 0, 0, 0, time.UTC))
+			require.Nil(t, err)
+			require.Equal(t, 50, count)
+		})
+	}
+}
+
+func TestJobsAndACLs(t *testing.T) {
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			require.Nil(t, s.Jobs().Put(&Job{Net: "axm", TxHash: "0x1", SentAt: 1}))
+			require.Nil(t, s.Jobs().Put(&Job{Net: "axm", TxHash: "0x2", SentAt: 2}))
+			require.Nil(t, s.ACLs().Put(&ACLEntry{List: "deny", Kind: "ip", Value: "10.0.0.1"}))
+
+			jobs, err := s.Jobs().List()
+			require.Nil(t, err)
+			require.Len(t, jobs, 2)
+			require.Nil(t, s.Jobs().Delete("0x1"))
+			jobs, err = s.Jobs().List()
+			require.Nil(t, err)
+			require.Equal(t, "0x2", jobs[0].TxHash)
+
+			entries, err := s.ACLs().List()
+			require.Nil(t, err)
+			require.Len(t, entries, 1)
+			require.Nil(t, s.ACLs().Delete("deny", "10.0.0.1"))
+			entries, err = s.ACLs().List()
+			require.Nil(t, err)
+			require.Len(t, entries, 0)
+		})
+	}
+}
+
+func TestAPIKeys(t *testing.T) {
+	day := time.Date(2023, 8, 1, 23, 0, 0, 0, time.UTC)
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			require.Nil(t, s.APIKeys().Put(&APIKey{ID: "abc", Hash: "abcdef", Tier: "ci"}))
+			key, err := s.APIKeys().Get("abcdef")
+			require.Nil(t, err)
+			require.Equal(t, "ci", key.Tier)
+			key, err = s.APIKeys().Get("missing")
+			require.Nil(t, err)
+			require.Nil(t, key)
+
+			require.Nil(t, s.APIKeys().SetUsage("abcdef", day, 1.5))
+			usage, err := s.APIKeys().Usage("abcdef", day)
+			require.Nil(t, err)
+			require.Equal(t, 1.5, usage)
+			// 按 UTC 日期分开统计
+			usage, err = s.APIKeys().Usage("abcdef", day.Add(2*time.Hour))
+			require.Nil(t, err)
+			require.Equal(t, float64(0), usage)
+
+			// 用量记录不会出现在 key 列表中
+			keys, err := s.APIKeys().List()
+			require.Nil(t, err)
+			require.Len(t, keys, 1)
+		})
+	}
+}
+
+func TestBudgets(t *testing.T) {
+	for name, s := range backends(t) {
+		t.Run(name, func(t *testing.T) {
+			for bucket := int64(1); bucket <= 5; bucket++ {
+				require.Nil(t, s.Budgets().Set("axm", bucket, 1))
+			}
+			require.Nil(t, s.Budgets().Set("other", 3, 10))
+
+			sum, err := s.Budgets().Sum("axm", 2, 5)
+			require.Nil(t, err)
+			require.Equal(t, float64(3), sum)
+
+			require.Nil(t, s.Budgets().Prune("axm", 4))
+			sum, err = s.Budgets().Sum("axm", 0, 100)
+			require.Nil(t, err)
+			require.Equal(t, float64(2), sum)
+
+			require.Nil(t, s.Budgets().Set("axm", 5, 0))
+			amount, err := s.Budgets().Get("axm", 5)
+			require.Nil(t, err)
+			require.Equal(t, float64(0), amount)
+
+			sum, err = s.Budgets().Sum("other", 0, 100)
+			require.Nil(t, err)
+			require.Equal(t, float64(10), sum)
+		})
+	}
+}
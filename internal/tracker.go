@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"faucet/internal/audit"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+)
+
+var _ Lifecycle = (*Tracker)(nil)
+
+const (
+	sourceTracker = "tracker"
+	// trackerInterval 检查待确认交易的间隔
+	trackerInterval = time.Minute
+	// trackerMinAge 之内的交易仍由 SendTra 自己等待确认
+	trackerMinAge = time.Minute
+	// trackerExpire 之后仍查不到回执的交易视为被丢弃
+	trackerExpire = time.Hour
+)
+
+// Tracker 处理 SendTra 没有等到确认的交易，包括等待回执超时和退出排空超时后保留在 store 中的交易：
+// 确认成功的按广播时间补记冷却和 ip 配额，执行失败或过期未上链的移出待确认列表，结果追加到审计账本
+type Tracker struct {
+	client  *Client
+	receipt func(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	now     func() time.Time
+	logger  logrus.FieldLogger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewTracker(c *Client) *Tracker {
+	return &Tracker{
+		client:  c,
+		receipt: c.receipt,
+		now:     time.Now,
+		logger:  c.logger,
+	}
+}
+
+func (t *Tracker) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.cancel = cancel
+	t.done = make(chan struct{})
+	go t.loop(ctx)
+	return nil
+}
+
+func (t *Tracker) Stop() error {
+	if t.cancel == nil {
+		return nil
+	}
+	t.cancel()
+	<-t.done
+	return nil
+}
+
+func (t *Tracker) loop(ctx context.Context) {
+	defer close(t.done)
+	ticker := time.NewTicker(trackerInterval)
+	defer ticker.Stop()
+	for {
+		if err := t.Check(ctx); err != nil {
+			t.logger.Errorf("check pending txs: %s", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check 检查一遍超过 trackerMinAge 的待确认交易
+func (t *Tracker) Check(ctx context.Context) error {
+	txs, err := t.client.PendingTxs()
+	if err != nil {
+		return err
+	}
+	now := t.now()
+	for _, tx := range txs {
+		age := now.Sub(time.Unix(tx.SentAt, 0))
+		if age < trackerMinAge {
+			continue
+		}
+		receipt, err := t.receipt(ctx, common.HexToHash(tx.TxHash))
+		switch {
+		case errors.Is(err, ethereum.NotFound):
+			if age >= trackerExpire {
+				t.logger.Warnf("pending tx %s not found on chain after %s, drop it", tx.TxHash, age)
+				t.resolve(tx, nil)
+			}
+		case err != nil:
+			if ctx.Err() != nil {
+				return nil
+			}
+			t.logger.Warnf("get receipt of pending tx %s: %s", tx.TxHash, err)
+		default:
+			t.resolve(tx, receipt)
+		}
+	}
+	return nil
+}
+
+// resolve 移出待确认列表，receipt 为空表示交易已被丢弃
+func (t *Tracker) resolve(tx *PendingTx, receipt *types.Receipt) {
+	c := t.client
+	// 补记和移出待确认列表之间不能插入同一地址或 ip 的领取检查
+	unlock := c.lockClaim(tx.Net, tx.Address, tx.IP)
+	defer unlock()
+	entry := &audit.Entry{
+		Source:   sourceTracker,
+		Net:      tx.Net,
+		Address:  tx.Address,
+		Decision: audit.DecisionAllow,
+		Amount:   tx.Amount,
+		TxHash:   tx.TxHash,
+		Status:   audit.StatusFailed,
+		Reason:   "not found on chain",
+	}
+	if receipt != nil {
+		entry.GasUsed = receipt.GasUsed
+		entry.Reason = "faucet transfer failed"
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			entry.Status = audit.StatusConfirmed
+			entry.Reason = ""
+			if err := c.recordClaim(tx); err != nil {
+				t.logger.Errorf("record claim of tx %s: %s", tx.TxHash, err)
+				return
+			}
+		}
+	}
+	c.RecordAudit(entry)
+	c.removePending(tx.TxHash)
+	t.logger.Infof("pending tx %s resolved as %s", tx.TxHash, entry.Status)
+}
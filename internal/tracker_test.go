@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"context"
+	"faucet/internal/audit"
+	"faucet/internal/cooldown"
+	"faucet/internal/loggers"
+	"faucet/internal/repo"
+	"faucet/internal/store"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackerCheck(t *testing.T) {
+	now := time.Date(2023, 8, 10, 12, 0, 0, 0, time.Local)
+	s := store.New(store.NewMemory())
+	c := &Client{
+		Config: &repo.Config{Axiom: repo.AXIOM{
+			Cooldown:     repo.Cooldown{Period: 24 * time.Hour, MaxClaims: 1, Mode: "sliding"},
+			IPDailyLimit: 5,
+			Tiers:        []repo.Tier{{Name: "ci", Cooldown: repo.Cooldown{MaxClaims: 3}}},
+		}},
+		store:     s,
+		Audit:     audit.New(s.Audit()),
+		cooldowns: cooldown.New(s.Claims(), func() time.Time { return now }),
+		logger:    loggers.Logger(loggers.ApiServer),
+	}
+	hash := func(i int) string { return common.BigToHash(big.NewInt(int64(i))).Hex() }
+	txs := []*PendingTx{
+		{Net: "axm", Address: "0xa1", TxHash: hash(1), Amount: 0.5, SentAt: now.Add(-10 * time.Minute).Unix(), Tier: "ci", IP: "10.0.0.1"},
+		{Net: "axm", Address: "0xa2", TxHash: hash(2), Amount: 0.5, SentAt: now.Add(-10 * time.Minute).Unix()},
+		{Net: "axm", Address: "0xa3", TxHash: hash(3), Amount: 0.5, SentAt: now.Add(-10 * time.Minute).Unix()},
+		{Net: "axm", Address: "0xa4", TxHash: hash(4), Amount: 0.5, SentAt: now.Add(-2 * time.Hour).Unix()},
+		// 仍由 SendTra 等待确认
+		{Net: "axm", Address: "0xa5", TxHash: hash(5), Amount: 0.5, SentAt: now.Add(-10 * time.Second).Unix()},
+	}
+	for _, tx := range txs {
+		require.Nil(t, s.Jobs().Put(tx))
+	}
+
+	// 0xa1 之前已经领取过两次，按 tier 的 max_claims 补记后三次都保留
+	earlier := []int64{now.Add(-3 * time.Hour).Unix(), now.Add(-2 * time.Hour).Unix()}
+	require.Nil(t, s.Claims().Put("axm", nativeToken, "0xa1", &store.Claim{SendTxTime: earlier[1], History: earlier}))
+
+	tracker := NewTracker(c)
+	tracker.now = func() time.Time { return now }
+	tracker.receipt = func(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+		switch txHash.Hex() {
+		case hash(1), hash(5):
+			return &types.Receipt{Status: types.ReceiptStatusSuccessful, GasUsed: 21000}, nil
+		case hash(2):
+			return &types.Receipt{Status: types.ReceiptStatusFailed}, nil
+		default:
+			return nil, ethereum.NotFound
+		}
+	}
+	require.Nil(t, tracker.Check(context.Background()))
+
+	pending, err := c.PendingTxs()
+	require.Nil(t, err)
+	var left []string
+	for _, tx := range pending {
+		left = append(left, tx.TxHash)
+	}
+	require.ElementsMatch(t, []string{hash(3), hash(5)}, left)
+
+	claim, err := s.Claims().Get("axm", nativeToken, "0xa1")
+	require.Nil(t, err)
+	require.NotNil(t, claim)
+	require.Equal(t, hash(1), claim.TxHash)
+	require.Len(t, claim.History, 3)
+	count, err := s.IPQuotas().Count("axm", "10.0.0.1", now)
+	require.Nil(t, err)
+	require.Equal(t, 1, count)
+	claim, err = s.Claims().Get("axm", nativeToken, "0xa2")
+	require.Nil(t, err)
+	require.Nil(t, claim)
+
+	entries, err := c.Audit.Range(now.Add(-time.Hour), time.Now().Add(time.Hour))
+	require.Nil(t, err)
+	statuses := make(map[string]string)
+	for _, entry := range entries {
+		require.Equal(t, sourceTracker, entry.Source)
+		statuses[entry.TxHash] = entry.Status
+	}
+	require.Equal(t, map[string]string{
+		hash(1): audit.StatusConfirmed,
+		hash(2): audit.StatusFailed,
+		hash(4): audit.StatusFailed,
+	}, statuses)
+}
@@ -2,7 +2,7 @@ package internal
 
 import (
 	"context"
-	"fmt"
+	"faucet/internal/errcode"
 	"math/big"
 	"regexp"
 
@@ -24,17 +24,17 @@ func sendTxAxm(c *Client, toAddr string, amount float64) (string, error) {
 	balanceNow, err := client.BalanceAt(context.Background(), common.HexToAddress(toAddr), nil)
 	if err != nil {
 		c.logger.Error(err)
-		return "", err
+		return "", errcode.Wrap(errcode.ChainUnavailable, err)
 	}
 	limit := floatToEtherBigInt(limit)
 	if balanceNow.Cmp(limit) >= 0 {
-		return "", fmt.Errorf("The address already has enough test tokens")
+		return "", errcode.New(errcode.BalanceSufficient, "The address already has enough test tokens")
 	}
 
 	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
 	if err != nil {
 		c.logger.Error(err)
-		return "", err
+		return "", errcode.Wrap(errcode.ChainUnavailable, err)
 	}
 
 	value := floatToEtherBigInt(amount) // in wei (1 eth)
@@ -42,7 +42,7 @@ func sendTxAxm(c *Client, toAddr string, amount float64) (string, error) {
 	gasPrice, err := client.SuggestGasPrice(context.Background())
 	if err != nil {
 		c.logger.Error(err)
-		return "", err
+		return "", errcode.Wrap(errcode.ChainUnavailable, err)
 	}
 	toAddress := common.HexToAddress(toAddr)
 	var data []byte
@@ -51,7 +51,7 @@ func sendTxAxm(c *Client, toAddr string, amount float64) (string, error) {
 	chainID, err := client.NetworkID(context.Background())
 	if err != nil {
 		c.logger.Error(err)
-		return "", err
+		return "", errcode.Wrap(errcode.ChainUnavailable, err)
 	}
 
 	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), c.axiomPrivateKey)
@@ -68,16 +68,27 @@ func sendTxAxm(c *Client, toAddr string, amount float64) (string, error) {
 			return "", err
 		}
 		if matched {
-			return "", fmt.Errorf("faucet error")
+			return "", errcode.New(errcode.FaucetEmpty, "faucet error")
 		}
 
-		return "", err
+		return "", errcode.Wrap(errcode.ChainUnavailable, err)
 	}
 	c.logger.Infof("axm tx sent: %s", signedTx.Hash().Hex())
 
 	return signedTx.Hash().Hex(), nil
 }
 
+// faucetBalance 水龙头账户的余额，单位 ether
+func faucetBalance(c *Client) (float64, error) {
+	balance, err := c.axiomClient.BalanceAt(context.Background(), c.axiomAuth.From, nil)
+	if err != nil {
+		return 0, errcode.Wrap(errcode.ChainUnavailable, err)
+	}
+	ether := new(big.Float).Quo(new(big.Float).SetInt(balance), new(big.Float).SetInt(floatToEtherBigInt(1)))
+	value, _ := ether.Float64()
+	return value, nil
+}
+
 func floatToEtherBigInt(value float64) *big.Int {
 	decimalMultiplier := new(big.Int)
 	decimalMultiplier.Exp(big.NewInt(10), big.NewInt(18), nil)
@@ -1,87 +1,77 @@
 package utils
 
 import (
-	"net"
-	"net/http"
-	"strconv"
+	"net/netip"
 	"strings"
 )
 
-// ClientIp ClientIP 尽最大努力实现获取客户端 IP 的算法。
-// 解析 X-Real-IP 和 X-Forwarded-For 以便于反向代理（nginx 或 haproxy）可以正常工作。
-func ClientIp(r *http.Request) string {
-	xForwardedFor := r.Header.Get("X-Forwarded-For")
-	ip := strings.TrimSpace(strings.Split(xForwardedFor, ",")[0])
-	if ip != "" {
-		return ip
-	}
-
-	ip = strings.TrimSpace(r.Header.Get("X-Real-Ip"))
-	if ip != "" {
-		return ip
-	}
-
-	ip, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
-	if err == nil {
-		return ip
-	}
-	return ""
+// localPrefixes 非公网地址段：内网、回环、链路本地、运营商级 NAT 以及 IPv6 ULA
+var localPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),      // 本网络
+	netip.MustParsePrefix("10.0.0.0/8"),     // A 类内网
+	netip.MustParsePrefix("100.64.0.0/10"),  // CGNAT
+	netip.MustParsePrefix("127.0.0.0/8"),    // 回环
+	netip.MustParsePrefix("169.254.0.0/16"), // 链路本地
+	netip.MustParsePrefix("172.16.0.0/12"),  // B 类内网 172.16.0.0～172.31.255.255
+	netip.MustParsePrefix("192.168.0.0/16"), // C 类内网
+	netip.MustParsePrefix("::/128"),         // 未指定
+	netip.MustParsePrefix("::1/128"),        // 回环
+	netip.MustParsePrefix("fc00::/7"),       // ULA
+	netip.MustParsePrefix("fe80::/10"),      // 链路本地
 }
 
-// ClientPublicIP 尽最大努力实现获取客户端公网 IP 的算法。
-// 解析 X-Real-IP 和 X-Forwarded-For 以便于反向代理（nginx 或 haproxy）可以正常工作。
-func ClientPublicIP(r *http.Request) string {
-	var ip string
-	for _, ip = range strings.Split(r.Header.Get("X-Forwarded-For"), ",") {
-		ip = strings.TrimSpace(ip)
-		if ip != "" && !IsLocalIp(ip) {
-			return ip
-		}
+// ParseIp 解析 IPv4/IPv6 地址，兼容带端口、方括号和 zone 的写法，IPv4-mapped IPv6 地址按 IPv4 处理
+func ParseIp(ip string) (netip.Addr, bool) {
+	ip = strings.TrimSpace(ip)
+	if addrPort, err := netip.ParseAddrPort(ip); err == nil {
+		return addrPort.Addr().Unmap().WithZone(""), true
 	}
-
-	ip = strings.TrimSpace(r.Header.Get("X-Real-Ip"))
-	if ip != "" && !IsLocalIp(ip) {
-		return ip
+	ip = strings.TrimSuffix(strings.TrimPrefix(ip, "["), "]")
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return netip.Addr{}, false
 	}
-
-	if ip, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr)); err == nil {
-		if !IsLocalIp(ip) {
-			return ip
-		}
-	}
-
-	return ""
+	return addr.Unmap().WithZone(""), true
 }
 
+// IsLocalIp 判断是否为非公网地址，无法解析的地址返回 false
 func IsLocalIp(ip string) bool {
-	/*
-		局域网（intranet）的IP地址范围包括：
-
-		10．0．0．0／8－－10．0．0．0～10．255．255．255（A类）
-
-		172．16．0．0／12－172．16．0．0－172．31．255．255（B类）
+	addr, ok := ParseIp(ip)
+	return ok && isLocalAddr(addr)
+}
 
-		192．168．0．0／16－－192．168．0．0～192．168．255．255（C类）
-	*/
-	ipAddr := strings.Split(ip, ".")
+// IsPublicIp 判断是否为可以作为客户端标识的公网地址
+func IsPublicIp(ip string) bool {
+	addr, ok := ParseIp(ip)
+	return ok && !isLocalAddr(addr)
+}
 
-	if strings.EqualFold(ipAddr[0], "10") {
-		return true
-	} else if strings.EqualFold(ipAddr[0], "172") {
-		addr, _ := strconv.Atoi(ipAddr[1])
-		if addr >= 16 && addr < 31 {
+func isLocalAddr(addr netip.Addr) bool {
+	for _, prefix := range localPrefixes {
+		if prefix.Contains(addr) {
 			return true
 		}
-	} else if strings.EqualFold(ipAddr[0], "192") && strings.EqualFold(ipAddr[1], "168") {
-		return true
 	}
 	return false
 }
 
-func GetRealIp(r *http.Request) string {
-	ip := ClientPublicIP(r)
-	if ip == "" {
-		ip = ClientIp(r)
+// SubnetKey 将 ip 归入所在网段，用于按网段计数限流：IPv6 用户通常持有整个 /64，单个地址计数没有意义。
+// 无法解析的 ip 原样返回
+func SubnetKey(ip string, v4Bits int, v6Bits int) string {
+	addr, ok := ParseIp(ip)
+	if !ok {
+		return ip
+	}
+	bits := v6Bits
+	if addr.Is4() {
+		bits = v4Bits
+	}
+	if bits <= 0 || bits > addr.BitLen() {
+		bits = addr.BitLen()
+	}
+	prefix, err := addr.Prefix(bits)
+	if err != nil {
+		return addr.String()
 	}
-	return ip
+	return prefix.String()
 }
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsLocalIp(t *testing.T) {
+	tests := []struct {
+		ip    string
+		local bool
+	}{
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"172.31.255.255", true},
+		{"172.32.0.1", false},
+		{"172.15.255.255", false},
+		{"192.168.1.1", true},
+		{"192.169.1.1", false},
+		{"127.0.0.1", true},
+		{"169.254.10.1", true},
+		{"100.64.0.1", true},
+		{"100.127.255.255", true},
+		{"100.128.0.1", false},
+		{"0.0.0.0", true},
+		{"8.8.8.8", false},
+		{"::1", true},
+		{"::", true},
+		{"fe80::1", true},
+		{"fe80::1%eth0", true},
+		{"fd12:3456::1", true},
+		{"fc00::1", true},
+		{"2001:db8::1", false},
+		{"::ffff:10.0.0.1", true},
+		{"::ffff:8.8.8.8", false},
+		{"[::1]:8080", true},
+		{"10.0.0.1:8080", true},
+		{"", false},
+		{"10", false},
+		{"not-an-ip", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			require.Equal(t, tt.local, IsLocalIp(tt.ip))
+		})
+	}
+}
+
+func TestIsPublicIp(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"8.8.8.8", true},
+		{"2001:4860:4860::8888", true},
+		{"192.168.0.1", false},
+		{"fd00::1", false},
+		{"garbage", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			require.Equal(t, tt.public, IsPublicIp(tt.ip))
+		})
+	}
+}
+
+func TestSubnetKey(t *testing.T) {
+	tests := []struct {
+		ip     string
+		v4Bits int
+		v6Bits int
+		want   string
+	}{
+		{"203.0.113.7", 32, 64, "203.0.113.7/32"},
+		{"203.0.113.7", 24, 64, "203.0.113.0/24"},
+		{"2001:db8:1:2:3:4:5:6", 32, 64, "2001:db8:1:2::/64"},
+		{"2001:db8:1:2:3:4:5:6", 32, 48, "2001:db8:1::/48"},
+		{"::ffff:203.0.113.7", 24, 64, "203.0.113.0/24"},
+		{"203.0.113.7", 0, 0, "203.0.113.7/32"},
+		{"2001:db8::1", 0, 200, "2001:db8::1/128"},
+		{"bad", 24, 64, "bad"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			require.Equal(t, tt.want, SubnetKey(tt.ip, tt.v4Bits, tt.v6Bits))
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/pires/go-proxyproto"
+)
+
+// IpResolver 只在请求来自可信代理时才解析转发头，防止客户端伪造 X-Forwarded-For 绕过按 ip 的限制
+type IpResolver struct {
+	trusted []netip.Prefix
+	// forwarded 同时解析 RFC 7239 Forwarded 头，优先于 X-Forwarded-For
+	forwarded bool
+}
+
+// NewIpResolver trustedProxies 支持单个 ip 或 CIDR
+func NewIpResolver(trustedProxies []string, forwarded bool) (*IpResolver, error) {
+	r := &IpResolver{forwarded: forwarded}
+	for _, proxy := range trustedProxies {
+		proxy = strings.TrimSpace(proxy)
+		if prefix, err := netip.ParsePrefix(proxy); err == nil {
+			r.trusted = append(r.trusted, prefix.Masked())
+			continue
+		}
+		addr, ok := ParseIp(proxy)
+		if !ok {
+			return nil, fmt.Errorf("invalid trusted proxy: %s", proxy)
+		}
+		r.trusted = append(r.trusted, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return r, nil
+}
+
+// IsTrusted 判断 ip 是否属于可信代理
+func (r *IpResolver) IsTrusted(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range r.trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIp 从直连地址开始，自右向左遍历转发链，返回第一个非可信代理的地址。
+// 转发链中出现无法解析的地址时，不再相信更左侧的内容，返回最后一个可信代理
+func (r *IpResolver) ClientIp(req *http.Request) string {
+	remote, ok := ParseIp(req.RemoteAddr)
+	if !ok {
+		return ""
+	}
+	if !r.IsTrusted(remote) {
+		return remote.String()
+	}
+
+	hops := r.forwardedHops(req)
+	if hops == nil {
+		if addr, ok := ParseIp(req.Header.Get("X-Real-Ip")); ok {
+			return addr.String()
+		}
+		return remote.String()
+	}
+
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := ParseIp(hops[i])
+		if !ok {
+			break
+		}
+		client = addr
+		if !r.IsTrusted(addr) {
+			break
+		}
+	}
+	return client.String()
+}
+
+// forwardedHops 按从客户端到代理的顺序返回转发链，请求不带转发头时返回 nil
+func (r *IpResolver) forwardedHops(req *http.Request) []string {
+	if r.forwarded {
+		if values := req.Header.Values("Forwarded"); len(values) != 0 {
+			return parseForwarded(values)
+		}
+	}
+	values := req.Header.Values("X-Forwarded-For")
+	if len(values) == 0 {
+		return nil
+	}
+	var hops []string
+	for _, value := range values {
+		for _, hop := range strings.Split(value, ",") {
+			hops = append(hops, strings.TrimSpace(hop))
+		}
+	}
+	return hops
+}
+
+// parseForwarded 提取 RFC 7239 Forwarded 头中每个元素的 for 参数，
+// unknown 或混淆标识（_xxx）原样保留，在遍历时视为无法解析的地址
+func parseForwarded(values []string) []string {
+	var hops []string
+	for _, value := range values {
+		for _, element := range strings.Split(value, ",") {
+			hop := ""
+			for _, pair := range strings.Split(element, ";") {
+				k, v, found := strings.Cut(strings.TrimSpace(pair), "=")
+				if found && strings.EqualFold(k, "for") {
+					hop = strings.Trim(v, `"`)
+				}
+			}
+			hops = append(hops, hop)
+		}
+	}
+	return hops
+}
+
+// ProxyProtocolListener 解析 PROXY protocol（v1/v2）头，只接受来自可信代理的头，其余连接按直连处理
+func (r *IpResolver) ProxyProtocolListener(ln net.Listener) net.Listener {
+	return &proxyproto.Listener{
+		Listener: ln,
+		Policy: func(upstream net.Addr) (proxyproto.Policy, error) {
+			if addr, ok := ParseIp(upstream.String()); ok && r.IsTrusted(addr) {
+				return proxyproto.USE, nil
+			}
+			return proxyproto.IGNORE, nil
+		},
+	}
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIpResolverClientIp(t *testing.T) {
+	resolver, err := NewIpResolver([]string{"10.0.0.0/8", "192.168.1.1", "fd00::/8"}, true)
+	require.Nil(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"untrusted remote ignores xff", "8.8.8.8:1234", map[string]string{"X-Forwarded-For": "1.1.1.1"}, "8.8.8.8"},
+		{"untrusted remote ignores real ip", "8.8.8.8:1234", map[string]string{"X-Real-Ip": "1.1.1.1"}, "8.8.8.8"},
+		{"trusted remote uses xff", "10.0.0.2:1234", map[string]string{"X-Forwarded-For": "1.1.1.1"}, "1.1.1.1"},
+		{"spoofed left hop is skipped", "10.0.0.2:1234", map[string]string{"X-Forwarded-For": "6.6.6.6, 1.1.1.1, 10.0.0.3"}, "1.1.1.1"},
+		{"all hops trusted", "10.0.0.2:1234", map[string]string{"X-Forwarded-For": "10.0.0.5, 192.168.1.1"}, "10.0.0.5"},
+		{"invalid hop stops walk", "10.0.0.2:1234", map[string]string{"X-Forwarded-For": "1.1.1.1, garbage, 10.0.0.3"}, "10.0.0.3"},
+		{"trusted remote uses real ip", "192.168.1.1:80", map[string]string{"X-Real-Ip": "2.2.2.2"}, "2.2.2.2"},
+		{"trusted remote without headers", "192.168.1.1:80", nil, "192.168.1.1"},
+		{"v6 proxy", "[fd00::1]:443", map[string]string{"X-Forwarded-For": "2001:db8::7"}, "2001:db8::7"},
+		{"forwarded header", "10.0.0.2:1234", map[string]string{
+			"Forwarded":       `for=6.6.6.6, for="[2001:db8::9]:4711";proto=https, for=10.0.0.9`,
+			"X-Forwarded-For": "3.3.3.3",
+		}, "2001:db8::9"},
+		{"forwarded unknown stops walk", "10.0.0.2:1234", map[string]string{"Forwarded": "for=1.1.1.1, for=unknown"}, "10.0.0.2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &http.Request{Header: http.Header{}, RemoteAddr: tt.remoteAddr}
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			require.Equal(t, tt.want, resolver.ClientIp(r))
+		})
+	}
+}
+
+func TestIpResolverForwardedDisabled(t *testing.T) {
+	resolver, err := NewIpResolver([]string{"10.0.0.0/8"}, false)
+	require.Nil(t, err)
+
+	r := &http.Request{Header: http.Header{}, RemoteAddr: "10.0.0.2:1234"}
+	r.Header.Set("Forwarded", "for=6.6.6.6")
+	r.Header.Set("X-Forwarded-For", "1.1.1.1")
+	require.Equal(t, "1.1.1.1", resolver.ClientIp(r))
+}
+
+func TestNewIpResolverInvalid(t *testing.T) {
+	_, err := NewIpResolver([]string{"10.0.0.0/33"}, false)
+	require.NotNil(t, err)
+}
@@ -0,0 +1,258 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: faucet.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Faucet_RequestDrip_FullMethodName       = "/faucet.v1.Faucet/RequestDrip"
+	Faucet_GetClaimStatus_FullMethodName    = "/faucet.v1.Faucet/GetClaimStatus"
+	Faucet_GetFaucetInfo_FullMethodName     = "/faucet.v1.Faucet/GetFaucetInfo"
+	Faucet_StreamClaimEvents_FullMethodName = "/faucet.v1.Faucet/StreamClaimEvents"
+)
+
+// FaucetClient is the client API for Faucet service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FaucetClient interface {
+	// RequestDrip 领取测试币，api key 通过 metadata x-api-key 携带
+	RequestDrip(ctx context.Context, in *RequestDripRequest, opts ...grpc.CallOption) (*RequestDripResponse, error)
+	// GetClaimStatus 查询领取交易是否已经上链
+	GetClaimStatus(ctx context.Context, in *GetClaimStatusRequest, opts ...grpc.CallOption) (*ClaimStatus, error)
+	// GetFaucetInfo 各 net 的领取数量、暂停状态、额度和余额
+	GetFaucetInfo(ctx context.Context, in *GetFaucetInfoRequest, opts ...grpc.CallOption) (*FaucetInfo, error)
+	// StreamClaimEvents 推送之后的每一次领取尝试，包括被拒绝的请求；
+	// 需要 metadata x-api-key，或与 /admin 相同的管理员证书、authorization: Bearer <token>
+	StreamClaimEvents(ctx context.Context, in *StreamClaimEventsRequest, opts ...grpc.CallOption) (Faucet_StreamClaimEventsClient, error)
+}
+
+type faucetClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFaucetClient(cc grpc.ClientConnInterface) FaucetClient {
+	return &faucetClient{cc}
+}
+
+func (c *faucetClient) RequestDrip(ctx context.Context, in *RequestDripRequest, opts ...grpc.CallOption) (*RequestDripResponse, error) {
+	out := new(RequestDripResponse)
+	err := c.cc.Invoke(ctx, Faucet_RequestDrip_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *faucetClient) GetClaimStatus(ctx context.Context, in *GetClaimStatusRequest, opts ...grpc.CallOption) (*ClaimStatus, error) {
+	out := new(ClaimStatus)
+	err := c.cc.Invoke(ctx, Faucet_GetClaimStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *faucetClient) GetFaucetInfo(ctx context.Context, in *GetFaucetInfoRequest, opts ...grpc.CallOption) (*FaucetInfo, error) {
+	out := new(FaucetInfo)
+	err := c.cc.Invoke(ctx, Faucet_GetFaucetInfo_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *faucetClient) StreamClaimEvents(ctx context.Context, in *StreamClaimEventsRequest, opts ...grpc.CallOption) (Faucet_StreamClaimEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Faucet_ServiceDesc.Streams[0], Faucet_StreamClaimEvents_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &faucetStreamClaimEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Faucet_StreamClaimEventsClient interface {
+	Recv() (*ClaimEvent, error)
+	grpc.ClientStream
+}
+
+type faucetStreamClaimEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *faucetStreamClaimEventsClient) Recv() (*ClaimEvent, error) {
+	m := new(ClaimEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FaucetServer is the server API for Faucet service.
+// All implementations must embed UnimplementedFaucetServer
+// for forward compatibility
+type FaucetServer interface {
+	// RequestDrip 领取测试币，api key 通过 metadata x-api-key 携带
+	RequestDrip(context.Context, *RequestDripRequest) (*RequestDripResponse, error)
+	// GetClaimStatus 查询领取交易是否已经上链
+	GetClaimStatus(context.Context, *GetClaimStatusRequest) (*ClaimStatus, error)
+	// GetFaucetInfo 各 net 的领取数量、暂停状态、额度和余额
+	GetFaucetInfo(context.Context, *GetFaucetInfoRequest) (*FaucetInfo, error)
+	// StreamClaimEvents 推送之后的每一次领取尝试，包括被拒绝的请求；
+	// 需要 metadata x-api-key，或与 /admin 相同的管理员证书、authorization: Bearer <token>
+	StreamClaimEvents(*StreamClaimEventsRequest, Faucet_StreamClaimEventsServer) error
+	mustEmbedUnimplementedFaucetServer()
+}
+
+// UnimplementedFaucetServer must be embedded to have forward compatible implementations.
+type UnimplementedFaucetServer struct {
+}
+
+func (UnimplementedFaucetServer) RequestDrip(context.Context, *RequestDripRequest) (*RequestDripResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestDrip not implemented")
+}
+func (UnimplementedFaucetServer) GetClaimStatus(context.Context, *GetClaimStatusRequest) (*ClaimStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetClaimStatus not implemented")
+}
+func (UnimplementedFaucetServer) GetFaucetInfo(context.Context, *GetFaucetInfoRequest) (*FaucetInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFaucetInfo not implemented")
+}
+func (UnimplementedFaucetServer) StreamClaimEvents(*StreamClaimEventsRequest, Faucet_StreamClaimEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamClaimEvents not implemented")
+}
+func (UnimplementedFaucetServer) mustEmbedUnimplementedFaucetServer() {}
+
+// UnsafeFaucetServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FaucetServer will
+// result in compilation errors.
+type UnsafeFaucetServer interface {
+	mustEmbedUnimplementedFaucetServer()
+}
+
+func RegisterFaucetServer(s grpc.ServiceRegistrar, srv FaucetServer) {
+	s.RegisterService(&Faucet_ServiceDesc, srv)
+}
+
+func _Faucet_RequestDrip_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestDripRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FaucetServer).RequestDrip(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Faucet_RequestDrip_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FaucetServer).RequestDrip(ctx, req.(*RequestDripRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Faucet_GetClaimStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetClaimStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FaucetServer).GetClaimStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Faucet_GetClaimStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FaucetServer).GetClaimStatus(ctx, req.(*GetClaimStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Faucet_GetFaucetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFaucetInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FaucetServer).GetFaucetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Faucet_GetFaucetInfo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FaucetServer).GetFaucetInfo(ctx, req.(*GetFaucetInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Faucet_StreamClaimEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamClaimEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FaucetServer).StreamClaimEvents(m, &faucetStreamClaimEventsServer{stream})
+}
+
+type Faucet_StreamClaimEventsServer interface {
+	Send(*ClaimEvent) error
+	grpc.ServerStream
+}
+
+type faucetStreamClaimEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *faucetStreamClaimEventsServer) Send(m *ClaimEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Faucet_ServiceDesc is the grpc.ServiceDesc for Faucet service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Faucet_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "faucet.v1.Faucet",
+	HandlerType: (*FaucetServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RequestDrip",
+			Handler:    _Faucet_RequestDrip_Handler,
+		},
+		{
+			MethodName: "GetClaimStatus",
+			Handler:    _Faucet_GetClaimStatus_Handler,
+		},
+		{
+			MethodName: "GetFaucetInfo",
+			Handler:    _Faucet_GetFaucetInfo_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamClaimEvents",
+			Handler:       _Faucet_StreamClaimEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "faucet.proto",
+}
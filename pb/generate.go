@@ -0,0 +1,4 @@
+// Package pb 是 faucet gRPC 接口的 protobuf 定义和生成代码
+package pb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative faucet.proto